@@ -1 +1,53 @@
 package config
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/search"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func TestQueryOptionsRoundTrip(t *testing.T) {
+	var stored []byte
+	var contentType string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			stored, _ = io.ReadAll(r.Body)
+			contentType = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(stored)
+	}))
+	defer server.Close()
+	options := search.Options{
+		Constraints: []search.Constraint{{Name: "tag", Collection: &search.CollectionConstraint{Prefix: "/tags/", Facet: search.Bool(true)}}},
+		SortOrders:  []search.SortOrder{{Direction: "descending", Score: &search.Score{}}},
+		ReturnQuery: search.Bool(true),
+	}
+	for _, format := range []int{handle.JSON, handle.XML} {
+		request := &search.OptionsHandle{Format: format}
+		request.Serialize(options)
+		if err := NewService(client).SetQueryOptions("tags", request, nil); err != nil {
+			t.Fatalf("SetQueryOptions Error = %v", err)
+		}
+		if contentType != handle.FormatEnumToMimeType(format) {
+			t.Errorf("SetQueryOptions Content-Type = %+v, Want = %+v", contentType, handle.FormatEnumToMimeType(format))
+		}
+		response := &search.OptionsHandle{Format: format}
+		if err := NewService(client).GetQueryOptions("tags", response); err != nil {
+			t.Fatalf("GetQueryOptions Error = %v", err)
+		}
+		got := response.Get()
+		got.XMLName = options.XMLName
+		if !reflect.DeepEqual(got, &options) {
+			t.Errorf("GetQueryOptions Results = %+v, Want = %+v", spew.Sdump(got), spew.Sdump(&options))
+		}
+	}
+}
@@ -160,12 +160,14 @@ func (s *Service) DeleteAllQueryOptions(response handle.ResponseHandle) error {
 	return deleteAllQueryOptions(s.client, response)
 }
 
-// SetQueryOptions shows all the installed REST extensions
+// SetQueryOptions installs query options under optionsName. Pass a
+// search.OptionsHandle to write typed search.Options.
 func (s *Service) SetQueryOptions(optionsName string, options handle.Handle, response handle.ResponseHandle) error {
 	return setQueryOptions(s.client, optionsName, options, response)
 }
 
-// GetQueryOptions returns the named REST query options
+// GetQueryOptions returns the named REST query options. Use a
+// search.OptionsHandle to read them as typed search.Options.
 func (s *Service) GetQueryOptions(name string, response handle.ResponseHandle) error {
 	return getQueryOptions(s.client, name, response)
 }
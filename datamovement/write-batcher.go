@@ -2,6 +2,7 @@ package datamovement
 
 import (
 	"context"
+	"errors"
 	"io"
 	"sync"
 	"time"
@@ -12,6 +13,11 @@ import (
 	"github.com/ryanjdew/go-marklogic-go/util"
 )
 
+// ErrDefaultMetadata is the error of a documents.DefaultMetadata entry sent
+// on the write channel of a WriteBatcher. Entries are spread across threads, so
+// default metadata is set for every batch with WithMetadata instead.
+var ErrDefaultMetadata = errors.New("default metadata entries are not supported by the WriteBatcher: use WithMetadata")
+
 // WriteBatcher writes documents in bulk
 type WriteBatcher struct {
 	client                 *clients.Client
@@ -26,6 +32,9 @@ type WriteBatcher struct {
 	forestInfo             []util.ForestInfo
 	transform              *util.Transform
 	transaction            *util.Transaction
+	metadata               *documents.Metadata
+	uriTemplate            *documents.URITemplate
+	codec                  documents.Codec
 }
 
 // WriteBatchIterator provides a pull-style iterator for WriteBatch results
@@ -72,6 +81,47 @@ func (wbr *WriteBatcher) WithTransform(transform *util.Transform) *WriteBatcher
 	return wbr
 }
 
+// WithMetadata set the default metadata for the documents written. Documents
+// with their own Metadata do not inherit it. documents.DefaultMetadata entries
+// on the write channel are not written; listeners get them in a WriteBatch
+// failed with ErrDefaultMetadata.
+func (wbr *WriteBatcher) WithMetadata(metadata *documents.Metadata) *WriteBatcher {
+	wbr.metadata = metadata
+	return wbr
+}
+
+// Metadata is the default metadata for the documents written
+func (wbr *WriteBatcher) Metadata() *documents.Metadata {
+	return wbr.metadata
+}
+
+// WithURITemplate set the template used to generate URIs for documents
+// written without a URI or URITemplate of their own
+func (wbr *WriteBatcher) WithURITemplate(uriTemplate *documents.URITemplate) *WriteBatcher {
+	wbr.uriTemplate = uriTemplate
+	return wbr
+}
+
+// URITemplate is the template used for documents without a URI
+func (wbr *WriteBatcher) URITemplate() *documents.URITemplate {
+	return wbr.uriTemplate
+}
+
+// WithCodec set the codec that encodes documents before they are written,
+// e.g. a documents.EncryptionCodec
+func (wbr *WriteBatcher) WithCodec(codec documents.Codec) *WriteBatcher {
+	wbr.codec = codec
+	for host, documentsService := range wbr.documentsServiceByHost {
+		wbr.documentsServiceByHost[host] = documentsService.WithCodec(codec)
+	}
+	return wbr
+}
+
+// Codec is the codec documents are encoded with
+func (wbr *WriteBatcher) Codec() documents.Codec {
+	return wbr.codec
+}
+
 // WithTransaction perform writes in given transaction
 func (wbr *WriteBatcher) WithTransaction(transaction *util.Transaction) *WriteBatcher {
 	wbr.transaction = transaction
@@ -103,7 +153,7 @@ func (wbr *WriteBatcher) RemoveListener(listener chan<- *WriteBatch) *WriteBatch
 
 // Run the WriteBatcher
 func (wbr *WriteBatcher) Run() *WriteBatcher {
-	hosts := make([]string, len(wbr.documentsServiceByHost))
+	hosts := make([]string, 0, len(wbr.documentsServiceByHost))
 	for host := range wbr.documentsServiceByHost {
 		hosts = append(hosts, host)
 	}
@@ -141,6 +191,14 @@ func (wbr *WriteBatcher) Wait() *WriteBatcher {
 	return wbr
 }
 
+// withURITemplate gives a document without a URI the WriteBatcher's URI template
+func (wbr *WriteBatcher) withURITemplate(doc *documents.DocumentDescription) *documents.DocumentDescription {
+	if doc.URI == "" && doc.URITemplate == nil {
+		doc.URITemplate = wbr.uriTemplate
+	}
+	return doc
+}
+
 func runWriteThread(writeBatcher *WriteBatcher, writeChannel <-chan *documents.DocumentDescription, documentsService *documents.Service) {
 	listeners := writeBatcher.listeners
 	batchSizeInt := int(writeBatcher.BatchSize())
@@ -155,15 +213,20 @@ func runWriteThread(writeBatcher *WriteBatcher, writeChannel <-chan *documents.D
 			}
 		}
 		writeDoc, ok := <-writeChannel
-		if writeDoc != nil {
-			writeBatch.documentDescriptions = append(writeBatch.documentDescriptions, writeDoc)
+		if writeDoc != nil && writeDoc.IsDefaultMetadata() {
+			rejected := rejectDefaultMetadata(writeDoc, documentsService)
+			for _, listener := range listeners {
+				listener <- rejected
+			}
+		} else if writeDoc != nil {
+			writeBatch.documentDescriptions = append(writeBatch.documentDescriptions, writeBatcher.withURITemplate(writeDoc))
 			if len(writeBatch.documentDescriptions) >= batchSizeInt {
-				submitBatch(writeBatch, writeBatcher.transform, writeBatcher.transaction, listeners)
+				submitBatch(writeBatch, writeBatcher.metadata, writeBatcher.transform, writeBatcher.transaction, listeners)
 				writeBatch = nil
 			}
 		} else if !ok && len(writeChannel) == 0 {
 			if len(writeBatch.documentDescriptions) > 0 {
-				submitBatch(writeBatch, writeBatcher.transform, writeBatcher.transaction, listeners)
+				submitBatch(writeBatch, writeBatcher.metadata, writeBatcher.transform, writeBatcher.transaction, listeners)
 				writeBatch = nil
 			}
 			return
@@ -186,12 +249,18 @@ func runWriteThreadIterator(writeBatcher *WriteBatcher, writeChannel <-chan *doc
 		case <-ctx.Done():
 			return
 		case writeDoc, ok := <-writeChannel:
-			if writeDoc != nil {
-				writeBatch.documentDescriptions = append(writeBatch.documentDescriptions, writeDoc)
+			if writeDoc != nil && writeDoc.IsDefaultMetadata() {
+				select {
+				case <-ctx.Done():
+					return
+				case results <- rejectDefaultMetadata(writeDoc, documentsService):
+				}
+			} else if writeDoc != nil {
+				writeBatch.documentDescriptions = append(writeBatch.documentDescriptions, writeBatcher.withURITemplate(writeDoc))
 				if len(writeBatch.documentDescriptions) >= batchSizeInt {
 					// submit and forward via results
 					ch := make(chan *WriteBatch, 1)
-					submitBatch(writeBatch, writeBatcher.transform, writeBatcher.transaction, []chan<- *WriteBatch{ch})
+					submitBatch(writeBatch, writeBatcher.metadata, writeBatcher.transform, writeBatcher.transaction, []chan<- *WriteBatch{ch})
 					select {
 					case <-ctx.Done():
 						return
@@ -207,7 +276,7 @@ func runWriteThreadIterator(writeBatcher *WriteBatcher, writeChannel <-chan *doc
 			} else if !ok && len(writeChannel) == 0 {
 				if len(writeBatch.documentDescriptions) > 0 {
 					ch := make(chan *WriteBatch, 1)
-					submitBatch(writeBatch, writeBatcher.transform, writeBatcher.transaction, []chan<- *WriteBatch{ch})
+					submitBatch(writeBatch, writeBatcher.metadata, writeBatcher.transform, writeBatcher.transaction, []chan<- *WriteBatch{ch})
 					select {
 					case <-ctx.Done():
 						return
@@ -299,11 +368,27 @@ func (it *writeBatchIter) Close() error {
 	return nil
 }
 
-func submitBatch(writeBatch *WriteBatch, transform *util.Transform, transaction *util.Transaction, listeners []chan<- *WriteBatch) {
+// rejectDefaultMetadata returns a failed WriteBatch for a default metadata entry
+func rejectDefaultMetadata(writeDoc *documents.DocumentDescription, documentsService *documents.Service) *WriteBatch {
+	writeBatch := &WriteBatch{
+		documentsService:     documentsService,
+		documentDescriptions: []*documents.DocumentDescription{writeDoc},
+	}
+	return writeBatch.WithResult(&documents.WriteSetResult{Documents: []*documents.WriteSetDocument{}}, ErrDefaultMetadata)
+}
+
+func submitBatch(writeBatch *WriteBatch, metadata *documents.Metadata, transform *util.Transform, transaction *util.Transaction, listeners []chan<- *WriteBatch) {
 	if len(writeBatch.DocumentDescriptions()) > 0 {
+		var metadataHandle handle.Handle
+		if metadata != nil {
+			defaults := &documents.MetadataHandle{}
+			defaults.Serialize(*metadata)
+			metadataHandle = defaults
+		}
 		responseHandle := &handle.RawHandle{}
-		writeBatch.DocumentsService().WriteSet(writeBatch.DocumentDescriptions(), &documents.MetadataHandle{}, transform, transaction, responseHandle)
+		result, err := writeBatch.DocumentsService().WriteSetWithResult(writeBatch.DocumentDescriptions(), metadataHandle, transform, transaction, responseHandle)
 		writeBatch.WithResponse(responseHandle)
+		writeBatch.WithResult(result, err)
 
 		// provide writeBatch back to listeners
 		for _, listener := range listeners {
@@ -318,6 +403,8 @@ type WriteBatch struct {
 	documentDescriptions []*documents.DocumentDescription
 	timestamp            string
 	response             handle.ResponseHandle
+	result               *documents.WriteSetResult
+	err                  error
 }
 
 // DocumentsService used to write the documents
@@ -350,3 +437,21 @@ func (wb *WriteBatch) WithResponse(response handle.ResponseHandle) *WriteBatch {
 func (wb *WriteBatch) Response() handle.ResponseHandle {
 	return wb.response
 }
+
+// WithResult set the outcome of writing the batch
+func (wb *WriteBatch) WithResult(result *documents.WriteSetResult, err error) *WriteBatch {
+	wb.result = result
+	wb.err = err
+	return wb
+}
+
+// Result return the written URIs and MIME types, or the per-document errors
+// when the batch failed
+func (wb *WriteBatch) Result() *documents.WriteSetResult {
+	return wb.result
+}
+
+// Err return the error writing the batch, nil if it was written
+func (wb *WriteBatch) Err() error {
+	return wb.err
+}
@@ -0,0 +1,153 @@
+package datamovement
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	clients "github.com/ryanjdew/go-marklogic-go/clients"
+	documents "github.com/ryanjdew/go-marklogic-go/documents"
+	test "github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func TestWriteBatcherListenerResult(t *testing.T) {
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorResponse":{"message":"Invalid content in /bad.json"}}`))
+	}))
+	defer server.Close()
+	writeChannel := make(chan *documents.DocumentDescription, 2)
+	writeChannel <- &documents.DocumentDescription{URI: "/good.json", Content: bytes.NewBufferString(`{"a":1}`)}
+	writeChannel <- &documents.DocumentDescription{URI: "/bad.json", Content: bytes.NewBufferString(`{`)}
+	close(writeChannel)
+	listener := make(chan *WriteBatch, 1)
+	wbr := &WriteBatcher{
+		writeChannel:           writeChannel,
+		documentsServiceByHost: map[string]*documents.Service{"localhost": documents.NewService(client)},
+		clientsByHost:          map[string]*clients.Client{"localhost": client},
+		batchSize:              2,
+		threadCount:            1,
+	}
+	wbr.WithListener(listener).Run().Wait()
+	batch := <-listener
+	if batch.Err() == nil {
+		t.Fatalf("Expected error writing batch")
+	}
+	failed := batch.Result().Failed()
+	if len(failed) != 2 || failed[0].Err != documents.ErrBatchRolledBack || failed[1].URI != "/bad.json" || failed[1].Err != batch.Err() {
+		t.Errorf("Failed Results = %+v, Want = %+v", failed, "/good.json rolled back, /bad.json failed")
+	}
+}
+
+func TestWriteBatcherMetadata(t *testing.T) {
+	dispositions := make(chan string, 2)
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		part, _ := reader.NextPart()
+		body, _ := io.ReadAll(part)
+		dispositions <- part.Header.Get("Content-Disposition") + " " + strings.TrimSpace(string(body))
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	writeChannel := make(chan *documents.DocumentDescription, 2)
+	writeChannel <- &documents.DocumentDescription{URI: "/a.json", Content: bytes.NewBufferString(`{}`)}
+	writeChannel <- &documents.DocumentDescription{URI: "/b.json", Content: bytes.NewBufferString(`{}`)}
+	close(writeChannel)
+	wbr := &WriteBatcher{
+		writeChannel:           writeChannel,
+		documentsServiceByHost: map[string]*documents.Service{"localhost": documents.NewService(client)},
+		clientsByHost:          map[string]*clients.Client{"localhost": client},
+		batchSize:              1,
+		threadCount:            1,
+	}
+	wbr.WithMetadata(&documents.Metadata{Collections: []string{"load"}}).Run().Wait()
+	want := `inline; category=metadata {"collections":["load"]}`
+	for i := 0; i < 2; i++ {
+		if result := <-dispositions; result != want {
+			t.Errorf("First Part = %+v, Want = %+v", result, want)
+		}
+	}
+}
+
+func TestWriteBatcherDefaultMetadata(t *testing.T) {
+	client, server := test.Client(`{"documents":[]}`)
+	defer server.Close()
+	writeChannel := make(chan *documents.DocumentDescription, 2)
+	defaults := documents.DefaultMetadata(&documents.Metadata{Collections: []string{"load"}})
+	writeChannel <- defaults
+	writeChannel <- &documents.DocumentDescription{URI: "/a.json", Content: bytes.NewBufferString(`{}`)}
+	close(writeChannel)
+	listener := make(chan *WriteBatch, 2)
+	wbr := &WriteBatcher{
+		writeChannel:           writeChannel,
+		documentsServiceByHost: map[string]*documents.Service{"localhost": documents.NewService(client)},
+		clientsByHost:          map[string]*clients.Client{"localhost": client},
+		batchSize:              2,
+		threadCount:            1,
+	}
+	wbr.WithListener(listener).Run().Wait()
+	rejected, written := <-listener, <-listener
+	if rejected.Err() != ErrDefaultMetadata || rejected.DocumentDescriptions()[0] != defaults {
+		t.Errorf("Rejected Batch = %+v, Want = %+v", rejected, ErrDefaultMetadata)
+	}
+	if written.Err() != nil || len(written.DocumentDescriptions()) != 1 {
+		t.Errorf("Written Batch = %+v, Want = %+v", written, "/a.json")
+	}
+}
+
+func TestWriteBatcherURITemplate(t *testing.T) {
+	client, server := test.Client(`{"documents":[]}`)
+	defer server.Close()
+	writeChannel := make(chan *documents.DocumentDescription, 2)
+	generated := &documents.DocumentDescription{Content: bytes.NewBufferString(`{}`)}
+	named := &documents.DocumentDescription{URI: "/named.json", Content: bytes.NewBufferString(`{}`)}
+	writeChannel <- generated
+	writeChannel <- named
+	close(writeChannel)
+	wbr := &WriteBatcher{
+		writeChannel:           writeChannel,
+		documentsServiceByHost: map[string]*documents.Service{"localhost": documents.NewService(client)},
+		clientsByHost:          map[string]*clients.Client{"localhost": client},
+		batchSize:              2,
+		threadCount:            1,
+	}
+	wbr.WithURITemplate(&documents.URITemplate{Prefix: "/generated/"}).Run().Wait()
+	if !strings.HasPrefix(generated.URI, "/generated/") || !strings.HasSuffix(generated.URI, ".json") {
+		t.Errorf("Generated URI = %+v, Want = %+v", generated.URI, "/generated/<uuid>.json")
+	}
+	if named.URI != "/named.json" || named.URITemplate != nil {
+		t.Errorf("Named URI = %+v, Want = %+v", named.URI, "/named.json")
+	}
+}
+
+func TestWriteBatcherCodec(t *testing.T) {
+	bodies := make(chan string, 1)
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	writeChannel := make(chan *documents.DocumentDescription, 1)
+	writeChannel <- &documents.DocumentDescription{URI: "/p.json", Content: bytes.NewBufferString(`{"ssn":"123-45-6789"}`)}
+	close(writeChannel)
+	wbr := &WriteBatcher{
+		writeChannel:           writeChannel,
+		documentsServiceByHost: map[string]*documents.Service{"localhost": documents.NewService(client)},
+		clientsByHost:          map[string]*clients.Client{"localhost": client},
+		batchSize:              1,
+		threadCount:            1,
+	}
+	keys := &documents.Keys{Current: "k1", Values: map[string][]byte{"k1": []byte("0123456789abcdef")}}
+	wbr.WithCodec(documents.NewEncryptionCodec(keys, "ssn")).Run().Wait()
+	body := <-bodies
+	if strings.Contains(body, "123-45-6789") || !strings.Contains(body, `"encryption-key-id":"k1"`) {
+		t.Errorf("Batch Body = %+v, Want encrypted ssn with envelope", body)
+	}
+}
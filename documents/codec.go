@@ -0,0 +1,84 @@
+package documents
+
+import "bytes"
+
+// Codec transforms document content on its way to and from the server. Encode
+// is called on a copy of a document before it is written, so the caller's
+// document keeps its content, and may replace the Content, Format, MimeType and
+// Metadata of the copy; it must not modify the Metadata it is given, which can
+// be shared between documents, but should set a changed copy instead.
+// Decode is called on documents read with their metadata-values and must
+// leave documents it did not encode unchanged.
+type Codec interface {
+	Encode(doc *DocumentDescription) error
+	Decode(doc *DocumentDescription) error
+}
+
+// WithCodec returns a Service that encodes the documents it writes with
+// Write, WriteConcurrent, Create, WriteSet and WriteSetWithResult and decodes
+// the documents it reads with ReadDocument and ReadDocuments
+func (s *Service) WithCodec(codec Codec) *Service {
+	return &Service{client: s.client, codec: codec}
+}
+
+// Codec returns the codec of the Service, nil if it has none
+func (s *Service) Codec() Codec {
+	return s.codec
+}
+
+// encodeDocument returns a copy of doc encoded by codec, given defaults as its
+// metadata when it has none. The content of doc is buffered first so that doc
+// still holds it after the write, failed or not.
+func encodeDocument(codec Codec, doc *DocumentDescription, defaults *Metadata) (*DocumentDescription, error) {
+	content, err := readContent(doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Content != nil {
+		doc.Content = bytes.NewBuffer(content)
+	}
+	encoded := *doc
+	encoded.Content = bytes.NewBuffer(bytes.Clone(content))
+	if encoded.Metadata == nil {
+		encoded.Metadata = defaults
+	}
+	if err := codec.Encode(&encoded); err != nil {
+		return nil, err
+	}
+	return &encoded, nil
+}
+
+// encodeWriteSet returns the documents of a multi-document write encoded by
+// codec. A document without its own metadata is given the default metadata in
+// effect for it first, so the envelope metadata added by the codec does not
+// drop the defaults.
+func encodeWriteSet(codec Codec, documents []*DocumentDescription, defaults *Metadata) ([]*DocumentDescription, error) {
+	encoded := make([]*DocumentDescription, len(documents))
+	for i, doc := range documents {
+		if doc.defaultMetadata {
+			defaults = doc.Metadata
+			encoded[i] = doc
+			continue
+		}
+		encodedDoc, err := encodeDocument(codec, doc, defaults)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = encodedDoc
+	}
+	return encoded, nil
+}
+
+// decodeCategories adds the metadata-values category to a read when it would
+// not otherwise return them
+func decodeCategories(categories []string) []string {
+	if len(categories) == 0 {
+		return categories
+	}
+	for _, category := range categories {
+		if category == "metadata" || category == "metadata-values" {
+			return categories
+		}
+	}
+	return append(append([]string{}, categories...), "metadata-values")
+}
@@ -1,25 +1,53 @@
 package documents
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"strconv"
+	"sync"
 
 	"github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
 	util "github.com/ryanjdew/go-marklogic-go/util"
 )
 
-// DocumentDescription describes a document to write
+// sniffLength is how much content is inspected to detect a document's format
+const sniffLength = 512
+
+// DocumentDescription describes a document to write. Format is detected from
+// the URI extension and the content when it is left as the zero value (JSON);
+// MimeType, when set, is sent as the Content-Type as is. A non-zero VersionID
+// makes writes and deletes conditional on the document still being at that
+// version. When URI is empty one is generated from URITemplate.
 type DocumentDescription struct {
-	URI       string
-	Content   io.ReadWriter
-	Metadata  *Metadata
-	Format    int
-	VersionID int
+	URI             string
+	URITemplate     *URITemplate
+	Content         io.ReadWriter
+	Metadata        *Metadata
+	Format          int
+	MimeType        string
+	VersionID       int
+	defaultMetadata bool
+}
+
+// DefaultMetadata returns an entry for WriteSet that makes metadata the
+// default for the documents that follow it, up to the next default metadata
+// entry. Documents with their own Metadata do not inherit the default. A nil
+// metadata sends an empty JSON metadata part, which reverts the following
+// documents to the system default metadata.
+func DefaultMetadata(metadata *Metadata) *DocumentDescription {
+	return &DocumentDescription{Metadata: metadata, defaultMetadata: true}
+}
+
+// IsDefaultMetadata tells whether the entry was created by DefaultMetadata
+func (dd *DocumentDescription) IsDefaultMetadata() bool {
+	return dd.defaultMetadata
 }
 
 // GetFormat returns int that represents XML or JSON
@@ -27,10 +55,55 @@ func (dd *DocumentDescription) GetFormat() int {
 	return dd.Format
 }
 
+// ContentType returns the mime type to send for the document. When neither
+// MimeType nor Format is set the type is detected from the URI and the leading
+// bytes of content, and Format is updated to match.
+func (dd *DocumentDescription) ContentType(content []byte) string {
+	if dd.MimeType != "" {
+		return dd.MimeType
+	}
+	if dd.Format != handle.JSON {
+		return handle.FormatEnumToMimeType(dd.Format)
+	}
+	if len(content) > sniffLength {
+		content = content[:sniffLength]
+	}
+	mimeType := handle.DetectMimeType(dd.URI, content)
+	dd.Format = handle.MimeTypeToFormatEnum(mimeType)
+	return mimeType
+}
+
+// contentTypeAndBody detects the content type of a document without reading
+// its content into memory
+func contentTypeAndBody(doc *DocumentDescription) (string, io.Reader) {
+	peeked, body := sniffContent(doc)
+	return doc.ContentType(peeked), body
+}
+
+// sniffContent returns the leading bytes of the content of a document, when
+// they are needed to detect its type, and a reader of the whole content
+func sniffContent(doc *DocumentDescription) ([]byte, io.Reader) {
+	if doc.Content == nil {
+		return nil, nil
+	}
+	if buffer, ok := doc.Content.(*bytes.Buffer); ok {
+		return buffer.Bytes(), buffer
+	}
+	if doc.MimeType != "" || doc.Format != handle.JSON || handle.MimeTypeForURI(doc.URI) != "" {
+		return nil, doc.Content
+	}
+	buffered := bufio.NewReaderSize(doc.Content, sniffLength)
+	peeked, _ := buffered.Peek(sniffLength)
+	return peeked, buffered
+}
+
 // ToURIs returns a slice of URIs from a slice of DocumentDescription types
 func ToURIs(docs []*DocumentDescription) []string {
 	uris := []string{}
 	for _, doc := range docs {
+		if doc.defaultMetadata {
+			continue
+		}
 		uris = append(uris, doc.URI)
 	}
 	return uris
@@ -47,33 +120,98 @@ func read(c *clients.Client, uris []string, categories []string, transform *util
 	return util.Execute(c, req, response)
 }
 
-func write(c *clients.Client, documents []*DocumentDescription, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
-	channel := make(chan error)
-	var errReturn error
-	for _, doc := range documents {
-		go func(doc *DocumentDescription) {
-			metadata := doc.Metadata
-			params := buildParameters([]string{doc.URI}, nil, metadata.Collections, metadata.PermissionsMap(), metadata.Properties, transform)
-			params = util.AddDatabaseParam(params, c)
-			params = util.AddTransactionParam(params, transaction)
-			req, err := http.NewRequest("PUT", c.Base()+"/documents"+params, doc.Content)
-			if err == nil {
-				err = util.Execute(c, req, response)
-			}
-			channel <- err
-		}(doc)
-	}
-	for range documents {
-		if errReturn == nil {
-			errReturn = <-channel
-		} else {
-			<-channel
+func write(c *clients.Client, documents []*DocumentDescription, concurrency int, codec Codec, transform *util.Transform, transaction *util.Transaction) ([]*WriteResult, error) {
+	if transaction != nil && transaction.ID == "" {
+		transaction.Begin()
+	}
+	results := make([]*WriteResult, len(documents))
+	forEach(len(documents), concurrency, func(index int) {
+		results[index] = writeDocument(c, documents[index], codec, transform, transaction)
+	})
+	errs := []error{}
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
 		}
 	}
-	return errReturn
+	return results, errors.Join(errs...)
+}
+
+func writeDocument(c *clients.Client, doc *DocumentDescription, codec Codec, transform *util.Transform, transaction *util.Transaction) *WriteResult {
+	result := &WriteResult{URI: doc.URI}
+	if doc.defaultMetadata {
+		return result
+	}
+	if codec != nil {
+		// buffered so the content can be sniffed before it is encoded
+		var content []byte
+		if content, result.Err = readContent(doc); result.Err != nil {
+			return result
+		}
+		doc.Content = bytes.NewBuffer(content)
+	}
+	// the URI is assigned before the content type so that its extension,
+	// chosen from the sniffed content, sets the content type
+	peeked, body := sniffContent(doc)
+	if result.Err = assignURI(doc, peeked); result.Err != nil {
+		return result
+	}
+	result.URI = doc.URI
+	if codec != nil {
+		if doc, result.Err = encodeDocument(codec, doc, nil); result.Err != nil {
+			return result
+		}
+		peeked, body = sniffContent(doc)
+	}
+	metadata := doc.Metadata
+	if metadata == nil {
+		metadata = &Metadata{}
+	}
+	contentType := doc.ContentType(peeked)
+	params := buildParameters([]string{doc.URI}, nil, metadata.Collections, metadata.PermissionsMap(), metadata.Properties, transform)
+	params = util.MappedParameters(params, "value", metadata.MetadataValues)
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("PUT", c.Base()+"/documents"+params, body)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	req.Header.Add("Content-Type", contentType)
+	if doc.VersionID != 0 {
+		req.Header.Add("If-Match", strconv.Itoa(doc.VersionID))
+	}
+	response := &writeResponseHandle{RawHandle: &handle.RawHandle{Format: handle.JSON}, result: result}
+	result.Response = response.RawHandle
+	result.Err = versionConflict(util.Execute(c, req, response), doc.URI, doc.VersionID)
+	return result
 }
 
-func writeSet(c *clients.Client, documents []*DocumentDescription, metadata handle.Handle, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
+// forEach calls fn for every index below count from at most concurrency
+// goroutines at a time
+func forEach(count int, concurrency int, fn func(int)) {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < concurrency && i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func writeSet(c *clients.Client, documents []*DocumentDescription, metadata handle.Handle, codec Codec, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) (*WriteSetResult, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 	params := ""
@@ -82,63 +220,118 @@ func writeSet(c *clients.Client, documents []*DocumentDescription, metadata hand
 	}
 	params = util.AddDatabaseParam(params, c)
 	params = util.AddTransactionParam(params, transaction)
-	metaHeader := &textproto.MIMEHeader{}
-	metadataSerialized := metadata.Serialized()
-	if metadata.GetFormat() == handle.JSON {
-		metaHeader.Add("Content-Type", "application/json")
-	} else if metadata.GetFormat() == handle.JSON {
-		metaHeader.Add("Content-Type", "application/xml")
-	} else if metadata.GetFormat() == handle.TEXTPLAIN {
-		metaHeader.Add("Content-Type", "text/plain")
-	} else {
-		metaHeader.Add("Content-Type", "application/octet-stream")
+	if metadata != nil {
+		writeMetadataPart(writer, body, metadata.Serialized(), metadata.GetFormat(), "")
 	}
-	metaHeader.Add("Content-Disposition", "inline; category=metadata")
-	metaHeader.Add("Content-Length", strconv.Itoa(len(metadataSerialized)))
-	writer.CreatePart(*metaHeader)
-	body.Write([]byte(metadataSerialized))
 	for _, doc := range documents {
-		if doc.Metadata != nil {
-			docMetaHeader := &textproto.MIMEHeader{}
-			metadataHandle := &MetadataHandle{metadata: *doc.Metadata}
-			docMetadataSerialized := metadataHandle.Serialized()
-			if metadataHandle.GetFormat() == handle.XML {
-				docMetaHeader.Add("Content-Type", "application/xml")
-			} else {
-				docMetaHeader.Add("Content-Type", "application/json")
+		if !doc.defaultMetadata {
+			content, err := readContent(doc)
+			if err != nil {
+				return nil, err
 			}
-			docMetaHeader.Add("Content-Disposition", "inline; category=metadata; filename=\""+doc.URI+"\"")
-			docMetaHeader.Add("Content-Length", strconv.Itoa(len(docMetadataSerialized)))
-			writer.CreatePart(*docMetaHeader)
-			body.Write([]byte(docMetadataSerialized))
+			doc.Content = bytes.NewBuffer(content)
+			if err := assignURI(doc, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if codec != nil {
+		var defaults *Metadata
+		if metadataHandle, ok := metadata.(*MetadataHandle); ok {
+			defaults = &metadataHandle.metadata
+		}
+		var err error
+		if documents, err = encodeWriteSet(codec, documents, defaults); err != nil {
+			return nil, err
 		}
+	}
+	mimeTypes := make([]string, len(documents))
+	for i, doc := range documents {
 		var docContentBytes []byte
-		if doc.Content != nil {
-			docContentBytes, _ = io.ReadAll(doc.Content)
+		if !doc.defaultMetadata {
+			if doc.Content != nil {
+				docContentBytes, _ = io.ReadAll(doc.Content)
+			}
+			doc.Content = bytes.NewBuffer(docContentBytes)
+			mimeTypes[i] = doc.ContentType(docContentBytes)
+		}
+		if doc.defaultMetadata && doc.Metadata == nil {
+			writeMetadataPart(writer, body, "{}", handle.JSON, "")
+		} else if doc.defaultMetadata || doc.Metadata != nil {
+			metadataHandle := &MetadataHandle{}
+			if doc.Metadata != nil {
+				metadataHandle.metadata = *doc.Metadata
+			}
+			writeMetadataPart(writer, body, metadataHandle.Serialized(), metadataHandle.GetFormat(), doc.URI)
+		}
+		if doc.defaultMetadata {
+			continue
 		}
-		doc.Content = bytes.NewBuffer(docContentBytes)
 		header := &textproto.MIMEHeader{}
-		if doc.GetFormat() == handle.JSON {
-			header.Add("Content-Type", "application/json")
-		} else if doc.GetFormat() == handle.JSON {
-			header.Add("Content-Type", "application/xml")
-		} else if doc.GetFormat() == handle.TEXTPLAIN {
-			header.Add("Content-Type", "text/plain")
-		} else {
-			header.Add("Content-Type", "application/octet-stream")
+		header.Add("Content-Type", mimeTypes[i])
+		disposition := "attachment; filename=\"" + doc.URI + "\""
+		if doc.VersionID != 0 {
+			disposition = disposition + "; versionId=" + strconv.Itoa(doc.VersionID)
 		}
-		header.Add("Content-Disposition", "attachment; filename=\""+doc.URI+"\"")
+		header.Add("Content-Disposition", disposition)
 		header.Add("Content-Length", strconv.Itoa(len(docContentBytes)))
 		writer.CreatePart(*header)
 		body.Write(docContentBytes)
 	}
 	body.Write([]byte("\r\n--" + writer.Boundary() + "--"))
 	req, err := http.NewRequest("POST", c.Base()+"/documents"+params, body)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Add("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
-	if err == nil {
-		err = util.Execute(c, req, response)
+	resultHandle := &writeSetHandle{Format: handle.JSON}
+	err = versionConflict(util.Execute(c, req, resultHandle), "", 0)
+	if err != nil {
+		return failedWriteSetResult(documents, mimeTypes, err), err
+	}
+	if response != nil {
+		response.Deserialize(resultHandle.Bytes())
+		response.SetTimestamp(resultHandle.Timestamp())
+	}
+	return resultHandle.Get(), nil
+}
+
+// writeMetadataPart adds a metadata part to a multi-document write. Without a
+// URI the part sets the default metadata for the documents that follow.
+func writeMetadataPart(writer *multipart.Writer, body *bytes.Buffer, serialized string, format int, uri string) {
+	header := &textproto.MIMEHeader{}
+	header.Add("Content-Type", handle.FormatEnumToMimeType(format))
+	if uri == "" {
+		header.Add("Content-Disposition", "inline; category=metadata")
+	} else {
+		header.Add("Content-Disposition", "inline; category=metadata; filename=\""+uri+"\"")
+	}
+	header.Add("Content-Length", strconv.Itoa(len(serialized)))
+	writer.CreatePart(*header)
+	body.Write([]byte(serialized))
+}
+
+func deleteDocuments(c *clients.Client, uris []string, categories []string, temporalCollection string, versionID int, transaction *util.Transaction, response handle.ResponseHandle) error {
+	if len(uris) == 0 {
+		return errors.New("no document URIs to delete")
+	}
+	if versionID != 0 && len(uris) > 1 {
+		return errors.New("a version conditional delete accepts a single URI")
+	}
+	params := buildParameters(uris, categories, nil, nil, nil, nil)
+	if temporalCollection != "" {
+		params = params + "&temporal-collection=" + url.QueryEscape(temporalCollection)
+	}
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("DELETE", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return err
+	}
+	if versionID != 0 {
+		req.Header.Add("If-Match", strconv.Itoa(versionID))
 	}
-	return err
+	return versionConflict(util.Execute(c, req, response), uris[0], versionID)
 }
 
 func buildParameters(uris []string, categories []string, collections []string, permissions map[string]string, properties map[string]string, transform *util.Transform) string {
@@ -1,9 +1,21 @@
 package documents
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
 	"github.com/ryanjdew/go-marklogic-go/util"
 )
 
@@ -33,3 +45,150 @@ func TestBuildParameters(t *testing.T) {
 		t.Errorf("Build Parameters Results = %+v, Want = %+v", spew.Sdump(result), spew.Sdump(want))
 	}
 }
+
+func TestWriteSetDetectsFormat(t *testing.T) {
+	contentTypes := map[string]string{}
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			_, disposition, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			contentTypes[disposition["filename"]] = part.Header.Get("Content-Type")
+		}
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{
+		{URI: "/images/logo.png", Content: bytes.NewBuffer([]byte("\x89PNG\r\n\x1a\n"))},
+		{URI: "/no-extension", Content: bytes.NewBufferString("<root/>")},
+		{URI: "/data.json", Content: bytes.NewBufferString(`{"a":1}`)},
+		{URI: "/override.bin", Content: bytes.NewBufferString("a,b"), MimeType: "text/csv"},
+		{URI: "/explicit", Content: bytes.NewBufferString("text"), Format: handle.TEXTPLAIN},
+	}
+	_, err := writeSet(client, docs, &MetadataHandle{}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := map[string]string{
+		"":                 "application/json",
+		"/images/logo.png": "image/png",
+		"/no-extension":    "application/xml",
+		"/data.json":       "application/json",
+		"/override.bin":    "text/csv",
+		"/explicit":        "text/plain",
+	}
+	if !reflect.DeepEqual(contentTypes, want) {
+		t.Errorf("Content Types = %+v, Want = %+v", spew.Sdump(contentTypes), spew.Sdump(want))
+	}
+	if docs[0].Format != handle.BINARY || docs[1].Format != handle.XML {
+		t.Errorf("Detected Formats = %+v, %+v", docs[0].Format, docs[1].Format)
+	}
+}
+
+func TestWriteDetectsFormat(t *testing.T) {
+	contentTypes := make(chan string, 2)
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		contentTypes <- r.URL.Query().Get("uri") + " " + r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{
+		{URI: "/streamed", Content: struct{ io.ReadWriter }{bytes.NewBufferString("%PDF-1.7 ...")}, Metadata: &Metadata{}},
+		{URI: "/notes.md", Content: bytes.NewBufferString("# Notes"), Metadata: &Metadata{}},
+	}
+	if _, err := write(client, docs, 0, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	results := map[string]bool{<-contentTypes: true, <-contentTypes: true}
+	want := map[string]bool{"/streamed application/pdf": true, "/notes.md text/markdown": true}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Content Types = %+v, Want = %+v", results, want)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	var requests []*http.Request
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	service := NewService(client)
+	if err := service.Delete([]string{"/a.json", "/b.json"}, []string{"collections"}, "temporal", nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if err := service.DeleteIfVersion("/a.json", 1234, "", nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := []string{
+		"DELETE /documents?uri=%2Fa.json&uri=%2Fb.json&category=collections&temporal-collection=temporal ",
+		"DELETE /documents?uri=%2Fa.json 1234",
+	}
+	for i, r := range requests {
+		result := r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("If-Match")
+		if result != want[i] {
+			t.Errorf("Delete Request = %+v, Want = %+v", result, want[i])
+		}
+	}
+	if err := service.Delete(nil, nil, "", nil, nil); err == nil {
+		t.Errorf("Expected error deleting without URIs")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var active, maxActive int32
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			seen := atomic.LoadInt32(&maxActive)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxActive, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid " + r.URL.Query().Get("uri")))
+			return
+		}
+		w.Header().Set("ETag", "\"42\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{}
+	for i := 0; i < 20; i++ {
+		docs = append(docs, &DocumentDescription{URI: "/" + strconv.Itoa(i) + ".txt", Content: bytes.NewBufferString("ok")})
+	}
+	docs[7].Content = bytes.NewBufferString("fail")
+	results, err := NewService(client).WriteConcurrent(docs, 3, nil, nil)
+	if err == nil {
+		t.Errorf("Expected error writing invalid document")
+	}
+	if maxActive > 3 {
+		t.Errorf("Concurrent Requests = %+v, Want = %+v", maxActive, 3)
+	}
+	for i, result := range results {
+		if result.URI != docs[i].URI {
+			t.Errorf("Result URI = %+v, Want = %+v", result.URI, docs[i].URI)
+		}
+		if i == 7 {
+			var statusError *util.StatusError
+			if !errors.As(result.Err, &statusError) || statusError.Body != "invalid /7.txt" {
+				t.Errorf("Result = %+v, Want = %+v", result, "error for /7.txt")
+			}
+		} else if result.Err != nil || result.VersionID != 42 {
+			t.Errorf("Result = %+v, Want = %+v", result, "version 42")
+		}
+	}
+	for _, doc := range docs {
+		doc.Content = bytes.NewBufferString("ok")
+	}
+	if err := NewService(client).Write(docs, nil, nil, &handle.RawHandle{}); err != nil {
+		t.Errorf("Write Error = %+v, Want = %+v", err, nil)
+	}
+}
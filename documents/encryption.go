@@ -0,0 +1,354 @@
+package documents
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+// Keys of the envelope metadata-values written with encrypted documents
+const (
+	EncryptionKeyIDKey     = "encryption-key-id"
+	EncryptionAlgorithmKey = "encryption-algorithm"
+	EncryptionFieldsKey    = "encryption-fields"
+)
+
+// encryptionAlgorithm is the algorithm recorded in the envelope: AES in GCM
+// mode with the random nonce prepended to the ciphertext
+const encryptionAlgorithm = "AES-GCM"
+
+// KeyProvider supplies the keys of an EncryptionCodec. Keys must be 16, 24 or
+// 32 bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// EncryptionKey returns the ID and value of the key to encrypt with
+	EncryptionKey() (string, []byte, error)
+	// DecryptionKey returns the key with the given ID
+	DecryptionKey(id string) ([]byte, error)
+}
+
+// Keys is a KeyProvider holding its keys in memory. Current is the ID of the
+// key content is encrypted with; older keys stay in Values to decrypt content
+// written before a rotation.
+type Keys struct {
+	Current string
+	Values  map[string][]byte
+}
+
+// EncryptionKey returns the current key
+func (k *Keys) EncryptionKey() (string, []byte, error) {
+	key, err := k.DecryptionKey(k.Current)
+	return k.Current, key, err
+}
+
+// DecryptionKey returns the key with the given ID
+func (k *Keys) DecryptionKey(id string) ([]byte, error) {
+	key, ok := k.Values[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+	return key, nil
+}
+
+// EncryptionCodec is a Codec that encrypts document content on the client.
+// With fields it encrypts the values of those JSON properties, given as dot
+// separated paths from the root object, replacing each with a base64 string;
+// without fields it encrypts the whole document, which keeps its format. The
+// key ID, the algorithm and the encrypted fields are stored in the document's
+// metadata-values so the content can be decrypted when it is read back, and
+// are removed from the metadata of decoded documents.
+type EncryptionCodec struct {
+	keys   KeyProvider
+	fields []string
+}
+
+// NewEncryptionCodec creates an EncryptionCodec encrypting fields, or the
+// whole content when no fields are given, with keys from keys
+func NewEncryptionCodec(keys KeyProvider, fields ...string) *EncryptionCodec {
+	return &EncryptionCodec{keys: keys, fields: fields}
+}
+
+// Encode encrypts the content of doc and records the envelope in its metadata
+func (ec *EncryptionCodec) Encode(doc *DocumentDescription) error {
+	content, err := readContent(doc)
+	if err != nil {
+		return err
+	}
+	format := handle.MimeTypeToFormatEnum(doc.ContentType(content))
+	keyID, key, err := ec.keys.EncryptionKey()
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	envelope := map[string]string{EncryptionKeyIDKey: keyID, EncryptionAlgorithmKey: encryptionAlgorithm}
+	if len(ec.fields) == 0 {
+		content, err = sealContent(aead, format, content)
+	} else {
+		if format != handle.JSON {
+			return fmt.Errorf("cannot encrypt fields of %s: content is not JSON", doc.URI)
+		}
+		var encrypted []string
+		content, encrypted, err = sealFields(aead, content, ec.fields)
+		envelope[EncryptionFieldsKey] = strings.Join(encrypted, ",")
+		if err == nil && len(encrypted) == 0 {
+			doc.Content = bytes.NewBuffer(content)
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot encrypt %s: %w", doc.URI, err)
+	}
+	doc.Content = bytes.NewBuffer(content)
+	doc.Metadata = withMetadataValues(doc.Metadata, envelope, nil)
+	return nil
+}
+
+// Decode decrypts the content of a document written by Encode
+func (ec *EncryptionCodec) Decode(doc *DocumentDescription) error {
+	if doc.Metadata == nil || doc.Metadata.MetadataValues[EncryptionKeyIDKey] == "" {
+		return nil
+	}
+	values := doc.Metadata.MetadataValues
+	if algorithm := values[EncryptionAlgorithmKey]; algorithm != encryptionAlgorithm {
+		return fmt.Errorf("cannot decrypt %s: unsupported algorithm %q", doc.URI, algorithm)
+	}
+	key, err := ec.keys.DecryptionKey(values[EncryptionKeyIDKey])
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	content, err := readContent(doc)
+	if err != nil {
+		return err
+	}
+	if fields, ok := values[EncryptionFieldsKey]; ok {
+		content, err = openFields(aead, content, strings.Split(fields, ","))
+	} else {
+		content, err = openContent(aead, doc.Format, content)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decrypt %s: %w", doc.URI, err)
+	}
+	doc.Content = bytes.NewBuffer(content)
+	doc.Metadata = withMetadataValues(doc.Metadata, nil, []string{EncryptionKeyIDKey, EncryptionAlgorithmKey, EncryptionFieldsKey})
+	return nil
+}
+
+// readContent reads the whole content of a document
+func readContent(doc *DocumentDescription) ([]byte, error) {
+	if doc.Content == nil {
+		return []byte{}, nil
+	}
+	return io.ReadAll(doc.Content)
+}
+
+// withMetadataValues returns a copy of metadata with values set and the
+// removed keys deleted from its metadata-values
+func withMetadataValues(metadata *Metadata, values map[string]string, removed []string) *Metadata {
+	copied := &Metadata{}
+	if metadata != nil {
+		*copied = *metadata
+	}
+	copied.MetadataValues = make(map[string]string, len(copied.MetadataValues)+len(values))
+	if metadata != nil {
+		for key, value := range metadata.MetadataValues {
+			copied.MetadataValues[key] = value
+		}
+	}
+	for key, value := range values {
+		copied.MetadataValues[key] = value
+	}
+	for _, key := range removed {
+		delete(copied.MetadataValues, key)
+	}
+	if len(copied.MetadataValues) == 0 {
+		copied.MetadataValues = nil
+	}
+	return copied
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext, prepending a random nonce
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts ciphertext produced by seal
+func open(aead cipher.AEAD, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
+
+// encryptedXML wraps an encrypted XML document
+type encryptedXML struct {
+	XMLName    xml.Name `xml:"encrypted"`
+	Ciphertext string   `xml:",chardata"`
+}
+
+// encryptedJSON wraps an encrypted JSON document
+type encryptedJSON struct {
+	Ciphertext string `json:"ciphertext"`
+}
+
+// sealContent encrypts a whole document. JSON and XML documents become a
+// JSON object or XML element holding the base64 ciphertext and text documents
+// the base64 ciphertext; binary documents hold the ciphertext itself.
+func sealContent(aead cipher.AEAD, format int, content []byte) ([]byte, error) {
+	ciphertext, err := seal(aead, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+	switch format {
+	case handle.JSON:
+		return json.Marshal(encryptedJSON{Ciphertext: encoded})
+	case handle.XML:
+		return xml.Marshal(encryptedXML{Ciphertext: encoded})
+	case handle.BINARY, handle.UNKNOWN:
+		return ciphertext, nil
+	}
+	return []byte(encoded), nil
+}
+
+// openContent decrypts a document encrypted by sealContent
+func openContent(aead cipher.AEAD, format int, content []byte) ([]byte, error) {
+	encoded := string(content)
+	switch format {
+	case handle.JSON:
+		wrapper := encryptedJSON{}
+		if err := json.Unmarshal(content, &wrapper); err != nil {
+			return nil, err
+		}
+		encoded = wrapper.Ciphertext
+	case handle.XML:
+		wrapper := encryptedXML{}
+		if err := xml.Unmarshal(content, &wrapper); err != nil {
+			return nil, err
+		}
+		encoded = wrapper.Ciphertext
+	case handle.BINARY, handle.UNKNOWN:
+		return open(aead, content, nil)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, ciphertext, nil)
+}
+
+// sealFields encrypts the values of the JSON properties at paths, returning
+// the paths that were present. The path is authenticated with each value so
+// encrypted values cannot be moved between fields.
+func sealFields(aead cipher.AEAD, content []byte, paths []string) ([]byte, []string, error) {
+	root, err := decodeJSONObject(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted := []string{}
+	for _, path := range paths {
+		parent, name := jsonField(root, path)
+		value, ok := parent[name]
+		if !ok {
+			continue
+		}
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		ciphertext, err := seal(aead, plaintext, []byte(path))
+		if err != nil {
+			return nil, nil, err
+		}
+		parent[name] = base64.StdEncoding.EncodeToString(ciphertext)
+		encrypted = append(encrypted, path)
+	}
+	content, err = json.Marshal(root)
+	return content, encrypted, err
+}
+
+// openFields decrypts the JSON properties encrypted by sealFields
+func openFields(aead cipher.AEAD, content []byte, paths []string) ([]byte, error) {
+	root, err := decodeJSONObject(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		parent, name := jsonField(root, path)
+		encoded, ok := parent[name].(string)
+		if !ok {
+			return nil, fmt.Errorf("encrypted field %s is missing", path)
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := open(aead, ciphertext, []byte(path))
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(plaintext))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		parent[name] = value
+	}
+	return json.Marshal(root)
+}
+
+// decodeJSONObject decodes a JSON object keeping numbers as written
+func decodeJSONObject(content []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	root := map[string]any{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// jsonField returns the object holding the property at a dot separated path
+// and the name of the property. The object is empty when the path does not
+// lead to one.
+func jsonField(root map[string]any, path string) (map[string]any, string) {
+	names := strings.Split(path, ".")
+	parent := root
+	for _, name := range names[:len(names)-1] {
+		child, ok := parent[name].(map[string]any)
+		if !ok {
+			return map[string]any{}, names[len(names)-1]
+		}
+		parent = child
+	}
+	return parent, names[len(names)-1]
+}
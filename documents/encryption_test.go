@@ -0,0 +1,223 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+var testKeys = &Keys{
+	Current: "k2",
+	Values: map[string][]byte{
+		"k1": []byte("0123456789abcdef"),
+		"k2": []byte("0123456789abcdef0123456789abcdef"),
+	},
+}
+
+func TestEncryptionCodecFields(t *testing.T) {
+	codec := NewEncryptionCodec(testKeys, "ssn", "address.street", "missing")
+	shared := &Metadata{Collections: []string{"people"}}
+	doc := &DocumentDescription{URI: "/p.json", Content: bytes.NewBufferString(`{"name":"Ada","ssn":"123-45-6789","address":{"street":"1 Main","zip":12345}}`), Metadata: shared}
+	if err := codec.Encode(doc); err != nil {
+		t.Fatalf("Encode Error = %v", err)
+	}
+	wantValues := map[string]string{EncryptionKeyIDKey: "k2", EncryptionAlgorithmKey: "AES-GCM", EncryptionFieldsKey: "ssn,address.street"}
+	if !reflect.DeepEqual(map[string]string(doc.Metadata.MetadataValues), wantValues) || !reflect.DeepEqual(doc.Metadata.Collections, []string{"people"}) {
+		t.Errorf("Envelope Results = %+v, Want = %+v", spew.Sdump(doc.Metadata), spew.Sdump(wantValues))
+	}
+	if shared.MetadataValues != nil {
+		t.Errorf("Shared Metadata = %+v, Want unchanged", spew.Sdump(shared))
+	}
+	encoded := doc.Content.(*bytes.Buffer).String()
+	if strings.Contains(encoded, "123-45-6789") || strings.Contains(encoded, "1 Main") || !strings.Contains(encoded, `"name":"Ada"`) {
+		t.Errorf("Encoded Content = %+v", encoded)
+	}
+	if err := codec.Decode(doc); err != nil {
+		t.Fatalf("Decode Error = %v", err)
+	}
+	want := `{"address":{"street":"1 Main","zip":12345},"name":"Ada","ssn":"123-45-6789"}`
+	if decoded := doc.Content.(*bytes.Buffer).String(); decoded != want {
+		t.Errorf("Decoded Content = %+v, Want = %+v", decoded, want)
+	}
+	if doc.Metadata.MetadataValues != nil {
+		t.Errorf("Decoded Metadata = %+v, Want no envelope", spew.Sdump(doc.Metadata))
+	}
+}
+
+func TestEncryptionCodecContent(t *testing.T) {
+	codec := NewEncryptionCodec(testKeys)
+	for _, doc := range []*DocumentDescription{
+		{URI: "/a.json", Content: bytes.NewBufferString(`{"a":1}`)},
+		{URI: "/a.xml", Content: bytes.NewBufferString(`<a>1</a>`)},
+		{URI: "/a.txt", Content: bytes.NewBufferString(`a 1`)},
+		{URI: "/a.bin", Content: bytes.NewBufferString("\x00\x01"), Format: handle.BINARY},
+	} {
+		original := doc.Content.(*bytes.Buffer).String()
+		if err := codec.Encode(doc); err != nil {
+			t.Fatalf("Encode %s Error = %v", doc.URI, err)
+		}
+		encoded := doc.Content.(*bytes.Buffer).String()
+		if strings.Contains(encoded, original) || handle.MimeTypeToFormatEnum(doc.ContentType([]byte(encoded))) != doc.Format {
+			t.Errorf("Encoded %s Content = %+v", doc.URI, encoded)
+		}
+		if err := codec.Decode(doc); err != nil {
+			t.Fatalf("Decode %s Error = %v", doc.URI, err)
+		}
+		if decoded := doc.Content.(*bytes.Buffer).String(); decoded != original {
+			t.Errorf("Decoded %s Content = %+v, Want = %+v", doc.URI, decoded, original)
+		}
+	}
+}
+
+func TestEncryptionCodecKeys(t *testing.T) {
+	old := NewEncryptionCodec(&Keys{Current: "k1", Values: testKeys.Values}, "ssn")
+	doc := &DocumentDescription{URI: "/p.json", Content: bytes.NewBufferString(`{"ssn":"1"}`)}
+	if err := old.Encode(doc); err != nil {
+		t.Fatalf("Encode Error = %v", err)
+	}
+	if err := NewEncryptionCodec(testKeys, "ssn").Decode(doc); err != nil {
+		t.Errorf("Decode with rotated keys Error = %v", err)
+	}
+	plain := &DocumentDescription{URI: "/plain.json", Content: bytes.NewBufferString(`{"ssn":"1"}`), Metadata: &Metadata{}}
+	if err := old.Decode(plain); err != nil || plain.Content.(*bytes.Buffer).String() != `{"ssn":"1"}` {
+		t.Errorf("Decode of plain document Error = %v, Content = %+v", err, plain.Content)
+	}
+	if err := old.Encode(doc); err != nil {
+		t.Fatalf("Encode Error = %v", err)
+	}
+	if err := NewEncryptionCodec(&Keys{Current: "k1", Values: map[string][]byte{"k1": testKeys.Values["k2"]}}).Decode(doc); err == nil {
+		t.Errorf("Decode with wrong key Results = nil, Want = error")
+	}
+}
+
+func TestServiceWithCodec(t *testing.T) {
+	var written []byte
+	var writeQuery string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			writeQuery = r.URL.RawQuery
+			written, _ = io.ReadAll(r.Body)
+			return
+		}
+		writer := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+		metadata, _ := json.Marshal(&Metadata{MetadataValues: map[string]string{
+			EncryptionKeyIDKey:     "k2",
+			EncryptionAlgorithmKey: "AES-GCM",
+			EncryptionFieldsKey:    "ssn",
+			"source":               "test",
+		}})
+		part, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}, "Content-Disposition": {`attachment; filename="/p.json"; category=metadata; format=json`}})
+		part.Write(metadata)
+		part, _ = writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}, "Content-Disposition": {`attachment; filename="/p.json"; category=content; format=json`}})
+		part.Write(written)
+		writer.Close()
+	}))
+	defer server.Close()
+	service := NewService(client).WithCodec(NewEncryptionCodec(testKeys, "ssn"))
+	doc := &DocumentDescription{URI: "/p.json", Content: bytes.NewBufferString(`{"ssn":"123-45-6789"}`)}
+	if _, err := service.WriteConcurrent([]*DocumentDescription{doc}, 1, nil, nil); err != nil {
+		t.Fatalf("Write Error = %v", err)
+	}
+	if bytes.Contains(written, []byte("123-45-6789")) || !strings.Contains(writeQuery, "value:encryption-key-id=k2") {
+		t.Errorf("Write Request = %s %s", writeQuery, written)
+	}
+	// the written document is left as it was
+	if content := doc.Content.(*bytes.Buffer).String(); content != `{"ssn":"123-45-6789"}` || doc.Metadata != nil {
+		t.Errorf("Written Document = %+v %+v, Want = %+v", content, doc.Metadata, `{"ssn":"123-45-6789"}`)
+	}
+	read, err := service.ReadDocument("/p.json", nil, nil)
+	if err != nil {
+		t.Fatalf("Read Error = %v", err)
+	}
+	if content := read.Content.(*bytes.Buffer).String(); content != `{"ssn":"123-45-6789"}` {
+		t.Errorf("Read Content = %+v, Want = %+v", content, `{"ssn":"123-45-6789"}`)
+	}
+	if want := map[string]string{"source": "test"}; !reflect.DeepEqual(map[string]string(read.Metadata.MetadataValues), want) {
+		t.Errorf("Read Metadata = %+v, Want = %+v", spew.Sdump(read.Metadata.MetadataValues), want)
+	}
+}
+
+func TestWriteSetWithCodecKeepsDefaults(t *testing.T) {
+	metadataParts := []string{}
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, part := range multipartParts(r) {
+			if strings.Contains(part, "category=metadata") {
+				metadataParts = append(metadataParts, part)
+			}
+		}
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{
+		DefaultMetadata(&Metadata{Collections: []string{"people"}}),
+		{URI: "/p.json", Content: bytes.NewBufferString(`{"ssn":"1"}`)},
+	}
+	service := NewService(client).WithCodec(NewEncryptionCodec(testKeys, "ssn"))
+	if err := service.WriteSet(docs, nil, nil, nil, nil); err != nil {
+		t.Fatalf("WriteSet Error = %v", err)
+	}
+	if len(metadataParts) != 2 || !strings.Contains(metadataParts[1], `"collections":["people"]`) || !strings.Contains(metadataParts[1], `"encryption-key-id":"k2"`) {
+		t.Errorf("Metadata Parts = %+v", spew.Sdump(metadataParts))
+	}
+}
+
+// failingKeys is a KeyProvider whose keys cannot be retrieved
+type failingKeys struct{}
+
+func (failingKeys) EncryptionKey() (string, []byte, error) {
+	return "", nil, errors.New("key store unavailable")
+}
+
+func (failingKeys) DecryptionKey(id string) ([]byte, error) {
+	return nil, errors.New("key store unavailable")
+}
+
+func TestWriteWithFailingKeys(t *testing.T) {
+	client, server := test.Client(`{"documents":[]}`)
+	defer server.Close()
+	service := NewService(client).WithCodec(NewEncryptionCodec(failingKeys{}))
+	doc := &DocumentDescription{URI: "/p.json", Content: bytes.NewBufferString(`{"ssn":"1"}`)}
+	if _, err := service.WriteConcurrent([]*DocumentDescription{doc}, 1, nil, nil); err == nil {
+		t.Errorf("Write Error = %v, Want = %+v", err, "key store unavailable")
+	}
+	if err := service.WriteSet([]*DocumentDescription{doc}, nil, nil, nil, nil); err == nil {
+		t.Errorf("WriteSet Error = %v, Want = %+v", err, "key store unavailable")
+	}
+	if content := doc.Content.(*bytes.Buffer).String(); content != `{"ssn":"1"}` {
+		t.Errorf("Content = %+v, Want = %+v", content, `{"ssn":"1"}`)
+	}
+}
+
+func TestCreateWithCodec(t *testing.T) {
+	var written []byte
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		written, _ = io.ReadAll(r.Body)
+		w.Header().Set("Location", "/v1/documents?uri=%2Fpeople%2F1234.json")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	service := NewService(client).WithCodec(NewEncryptionCodec(testKeys, "ssn"))
+	doc := &DocumentDescription{Content: bytes.NewBufferString(`{"ssn":"123-45-6789"}`)}
+	if _, err := service.Create(doc, "/people/", "json", nil, nil); err != nil {
+		t.Fatalf("Create Error = %v", err)
+	}
+	if bytes.Contains(written, []byte("123-45-6789")) {
+		t.Errorf("Create Request = %s", written)
+	}
+	// only the server assigned URI is copied back to the created document
+	if content := doc.Content.(*bytes.Buffer).String(); content != `{"ssn":"123-45-6789"}` || doc.Metadata != nil || doc.URI != "/people/1234.json" {
+		t.Errorf("Created Document = %+v %+v %+v, Want = %+v", doc.URI, content, doc.Metadata, `{"ssn":"123-45-6789"}`)
+	}
+}
@@ -0,0 +1,86 @@
+package documents
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// defaultConcurrency is the number of simultaneous requests used by batched
+// calls when the caller does not choose one
+const defaultConcurrency = 8
+
+// DocumentInfo describes a document as reported by a HEAD request, without
+// its content
+type DocumentInfo struct {
+	URI           string
+	Exists        bool
+	ContentType   string
+	ContentLength int64
+	Format        int
+	VersionID     int
+}
+
+// versionIDFromETag converts an ETag header into a version ID, returning 0
+// when there is none
+func versionIDFromETag(etag string) int {
+	etag = strings.Trim(strings.TrimPrefix(etag, "W/"), "\"")
+	versionID, err := strconv.Atoi(etag)
+	if err != nil {
+		return 0
+	}
+	return versionID
+}
+
+func head(c *clients.Client, uri string, transaction *util.Transaction) (*DocumentInfo, error) {
+	params := buildParameters([]string{uri}, nil, nil, nil, nil, nil)
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("HEAD", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+	clients.ApplyAuth(c, req)
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	info := &DocumentInfo{URI: uri}
+	if resp.StatusCode == http.StatusNotFound {
+		return info, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, &util.StatusError{StatusCode: resp.StatusCode}
+	}
+	info.Exists = true
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.ContentLength, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	info.VersionID = versionIDFromETag(resp.Header.Get("ETag"))
+	info.Format = handle.DocumentFormatToFormatEnum(resp.Header.Get("vnd.marklogic.document-format"))
+	if info.Format == handle.UNKNOWN {
+		info.Format = handle.MimeTypeToFormatEnum(info.ContentType)
+	}
+	return info, nil
+}
+
+func headAll(c *clients.Client, uris []string, concurrency int, transaction *util.Transaction) ([]*DocumentInfo, error) {
+	if transaction != nil && transaction.ID == "" {
+		transaction.Begin()
+	}
+	infos := make([]*DocumentInfo, len(uris))
+	errs := make([]error, len(uris))
+	forEach(len(uris), concurrency, func(index int) {
+		infos[index], errs[index] = head(c, uris[index], transaction)
+	})
+	for _, err := range errs {
+		if err != nil {
+			return infos, err
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,90 @@
+package documents
+
+import (
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func headHandler(active *int32, maxActive *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(active, 1)
+		defer atomic.AddInt32(active, -1)
+		for {
+			seen := atomic.LoadInt32(maxActive)
+			if current <= seen || atomic.CompareAndSwapInt32(maxActive, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch r.URL.Query().Get("uri") {
+		case "/missing.json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/error.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", "42")
+			w.Header().Set("ETag", "\"14877656183327160\"")
+			w.Header().Set("vnd.marklogic.document-format", "json")
+		}
+	})
+}
+
+func TestExists(t *testing.T) {
+	var active, maxActive int32
+	client, server := test.ClientWithHandler(headHandler(&active, &maxActive))
+	defer server.Close()
+	service := NewService(client)
+	info, err := service.Exists("/doc.json", nil)
+	want := &DocumentInfo{
+		URI:           "/doc.json",
+		Exists:        true,
+		ContentType:   "application/json",
+		ContentLength: 42,
+		Format:        handle.JSON,
+		VersionID:     14877656183327160,
+	}
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Exists Results = %+v, Want = %+v", spew.Sdump(info), spew.Sdump(want))
+	}
+	info, err = service.Exists("/missing.json", nil)
+	if err != nil || info.Exists {
+		t.Errorf("Missing document = %+v, %v", info, err)
+	}
+	if _, err = service.Exists("/error.json", nil); err == nil {
+		t.Errorf("Expected error for status 500")
+	}
+}
+
+func TestExistsAll(t *testing.T) {
+	var active, maxActive int32
+	client, server := test.ClientWithHandler(headHandler(&active, &maxActive))
+	defer server.Close()
+	uris := []string{"/1.json", "/missing.json", "/3.json", "/4.json", "/5.json", "/6.json", "/7.json"}
+	infos, err := NewService(client).ExistsAll(uris, 2, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	for i, info := range infos {
+		if info.URI != uris[i] || info.Exists != (uris[i] != "/missing.json") {
+			t.Errorf("ExistsAll Result %d = %+v", i, info)
+		}
+	}
+	if maxActive > 2 {
+		t.Errorf("Concurrent requests = %d, Want <= 2", maxActive)
+	}
+	infos, err = NewService(client).ExistsAll([]string{"/1.json", "/error.json"}, 0, nil)
+	if err == nil || infos[0] == nil || infos[1] != nil {
+		t.Errorf("ExistsAll with failure = %+v, %v", infos, err)
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
 	"strings"
 
+	"github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
 	util "github.com/ryanjdew/go-marklogic-go/util"
 )
@@ -128,3 +134,116 @@ type Permission struct {
 	RoleName   string   `xml:"http://marklogic.com/rest-api role-name" json:"role-name,omitempty"`
 	Capability []string `xml:"http://marklogic.com/rest-api capability" json:"capabilities,omitempty"`
 }
+
+// metadataCategories are the categories that can be read or written
+// independently of content
+var metadataCategories = map[string]bool{
+	"metadata":        true,
+	"collections":     true,
+	"permissions":     true,
+	"properties":      true,
+	"quality":         true,
+	"metadata-values": true,
+}
+
+func checkMetadataCategories(categories []string) ([]string, error) {
+	if len(categories) == 0 {
+		return []string{"metadata"}, nil
+	}
+	for _, category := range categories {
+		if !metadataCategories[category] {
+			return nil, errors.New("invalid metadata category: " + category)
+		}
+	}
+	return categories, nil
+}
+
+// metadataMapHandle reads metadata parts into Metadata keyed by URI
+type metadataMapHandle struct {
+	*handle.RawHandle
+	uris     []string
+	metadata map[string]*Metadata
+}
+
+// AcceptResponse handles an *http.Response
+func (mh *metadataMapHandle) AcceptResponse(resp *http.Response) error {
+	mh.SetTimestamp(resp.Header.Get("ML-Effective-Timestamp"))
+	return eachPart(resp, func(header textproto.MIMEHeader, disposition map[string]string, content []byte) error {
+		uri := disposition["filename"]
+		if uri == "" && len(mh.uris) == 1 {
+			uri = mh.uris[0]
+		}
+		metadata := &Metadata{}
+		var err error
+		if handle.MimeTypeToFormatEnum(header.Get("Content-Type")) == handle.XML {
+			err = xml.Unmarshal(content, metadata)
+		} else {
+			err = json.Unmarshal(content, metadata)
+		}
+		if err != nil {
+			return err
+		}
+		mh.metadata[uri] = metadata
+		return nil
+	})
+}
+
+func readMetadata(c *clients.Client, uris []string, categories []string, transaction *util.Transaction) (map[string]*Metadata, error) {
+	if len(uris) == 0 {
+		return nil, errors.New("no document URIs to read metadata for")
+	}
+	categories, err := checkMetadataCategories(categories)
+	if err != nil {
+		return nil, err
+	}
+	params := buildParameters(uris, categories, nil, nil, nil, nil) + "&format=json"
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("GET", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", handle.FormatEnumToMimeType(handle.MIXED))
+	response := &metadataMapHandle{RawHandle: &handle.RawHandle{Format: handle.MIXED}, uris: uris, metadata: map[string]*Metadata{}}
+	if err := util.Execute(c, req, response); err != nil {
+		return nil, err
+	}
+	return response.metadata, nil
+}
+
+func writeMetadata(c *clients.Client, metadata map[string]*Metadata, categories []string, concurrency int, transaction *util.Transaction) error {
+	categories, err := checkMetadataCategories(categories)
+	if err != nil {
+		return err
+	}
+	if transaction != nil && transaction.ID == "" {
+		transaction.Begin()
+	}
+	uris := make([]string, 0, len(metadata))
+	for uri := range metadata {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	errs := make([]error, len(uris))
+	forEach(len(uris), concurrency, func(index int) {
+		uri := uris[index]
+		serialized, err := json.Marshal(metadata[uri])
+		if err != nil {
+			errs[index] = err
+			return
+		}
+		params := buildParameters([]string{uri}, categories, nil, nil, nil, nil) + "&format=json"
+		params = util.AddDatabaseParam(params, c)
+		params = util.AddTransactionParam(params, transaction)
+		req, err := http.NewRequest("PUT", c.Base()+"/documents"+params, bytes.NewReader(serialized))
+		if err != nil {
+			errs[index] = err
+			return
+		}
+		req.Header.Add("Content-Type", handle.FormatEnumToMimeType(handle.JSON))
+		if err := util.Execute(c, req, nil); err != nil {
+			errs[index] = fmt.Errorf("%s: %w", uri, err)
+		}
+	})
+	return errors.Join(errs...)
+}
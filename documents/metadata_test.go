@@ -2,8 +2,14 @@ package documents
 
 import (
 	"encoding/xml"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
 	"testing"
 
+	"github.com/davecgh/go-spew/spew"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
 	testHelper "github.com/ryanjdew/go-marklogic-go/test"
 )
@@ -41,3 +47,79 @@ func TestMetadataSerializeXML(t *testing.T) {
 	}
 	testHelper.RoundTripSerialization(t, "Metadata", metadata, &MetadataHandle{Format: handle.XML}, want)
 }
+
+func TestReadMetadata(t *testing.T) {
+	var request *http.Request
+	client, server := testHelper.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		writer := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+		for uri, body := range map[string]string{
+			"/a.json": `{"collections":["a"],"quality":1}`,
+			"/b.xml":  `{"collections":["b"],"permissions":[{"role-name":"reader","capabilities":["read"]}]}`,
+		} {
+			part, _ := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":        {"application/json"},
+				"Content-Disposition": {`attachment; filename="` + uri + `"; category=metadata; format=json`},
+			})
+			part.Write([]byte(body))
+		}
+		writer.Close()
+	}))
+	defer server.Close()
+	result, err := NewService(client).ReadMetadata([]string{"/a.json", "/b.xml"}, []string{"collections", "permissions", "quality"}, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := map[string]*Metadata{
+		"/a.json": {Collections: []string{"a"}, Quality: 1},
+		"/b.xml":  {Collections: []string{"b"}, Permissions: []Permission{{RoleName: "reader", Capability: []string{"read"}}}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Metadata Results = %+v, Want = %+v", spew.Sdump(result), spew.Sdump(want))
+	}
+	wantRequest := "/documents?uri=%2Fa.json&uri=%2Fb.xml&category=collections&category=permissions&category=quality&format=json multipart/mixed"
+	if got := request.URL.RequestURI() + " " + request.Header.Get("Accept"); got != wantRequest {
+		t.Errorf("Metadata Request = %+v, Want = %+v", got, wantRequest)
+	}
+	if _, err := NewService(client).ReadMetadata([]string{"/a.json"}, []string{"content"}, nil); err == nil {
+		t.Errorf("Expected error reading content as metadata")
+	}
+}
+
+func TestReadMetadataSingle(t *testing.T) {
+	client, server := testHelper.Client(`{"collections":["a"]}`)
+	defer server.Close()
+	result, err := NewService(client).ReadMetadata([]string{"/a.json"}, nil, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if result["/a.json"] == nil || !reflect.DeepEqual(result["/a.json"].Collections, []string{"a"}) {
+		t.Errorf("Metadata Results = %+v, Want = %+v", spew.Sdump(result), "/a.json in collection a")
+	}
+}
+
+func TestWriteMetadata(t *testing.T) {
+	requests := make(chan string, 2)
+	client, server := testHelper.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r.Method + " " + r.URL.RequestURI() + " " + string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	err := NewService(client).WriteMetadata(map[string]*Metadata{
+		"/a.json": {Collections: []string{"a"}},
+		"/b.xml":  {Collections: []string{"b"}},
+	}, []string{"collections"}, 2, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	result := map[string]bool{<-requests: true, <-requests: true}
+	want := map[string]bool{
+		`PUT /documents?uri=%2Fa.json&category=collections&format=json {"collections":["a"]}`: true,
+		`PUT /documents?uri=%2Fb.xml&category=collections&format=json {"collections":["b"]}`:  true,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Metadata Requests = %+v, Want = %+v", result, want)
+	}
+}
@@ -0,0 +1,47 @@
+package documents
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// eachPart calls fn with the headers, Content-Disposition parameters and
+// content of every part of a multipart response. A response that is not
+// multipart is passed to fn as a single part.
+func eachPart(resp *http.Response, fn func(header textproto.MIMEHeader, disposition map[string]string, content []byte) error) error {
+	defer resp.Body.Close()
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		content, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if len(content) == 0 {
+			return nil
+		}
+		_, disposition, _ := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+		return fn(textproto.MIMEHeader(resp.Header), disposition, content)
+	}
+	reader := multipart.NewReader(resp.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		_, disposition, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		if err := fn(part.Header, disposition, content); err != nil {
+			return err
+		}
+	}
+}
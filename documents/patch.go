@@ -0,0 +1,401 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// Positions for inserted content relative to the context node
+const (
+	PatchBefore    = "before"
+	PatchAfter     = "after"
+	PatchLastChild = "last-child"
+)
+
+// Path languages for JSON patches
+const (
+	XPathLanguage    = "xpath"
+	JSONPathLanguage = "jsonpath"
+)
+
+const (
+	restAPINamespace  = "http://marklogic.com/rest-api"
+	propertyNamespace = "http://marklogic.com/xdmp/property"
+)
+
+// patchOperation is a single insert, replace, replace-insert or delete
+type patchOperation struct {
+	kind       string
+	context    string
+	position   string
+	selectPath string
+	apply      string
+	content    interface{}
+	hasContent bool
+	metadata   bool
+}
+
+// PatchBuilder builds a partial update for a JSON or XML document. Content
+// operations address the document with XPath (or JSONPath for JSON documents);
+// metadata operations update collections, permissions, properties and quality
+// without rewriting the document.
+type PatchBuilder struct {
+	format     int
+	pathLang   string
+	namespaces map[string]string
+	operations []*patchOperation
+	err        error
+}
+
+// NewPatchBuilder returns a PatchBuilder for a document of the given format
+// (handle.JSON or handle.XML)
+func NewPatchBuilder(format int) *PatchBuilder {
+	return &PatchBuilder{format: format, pathLang: XPathLanguage, namespaces: map[string]string{}}
+}
+
+// PathLanguage sets the path language used by a JSON patch (xpath or jsonpath)
+func (pb *PatchBuilder) PathLanguage(pathLang string) *PatchBuilder {
+	pb.pathLang = pathLang
+	return pb
+}
+
+// Namespace binds a prefix used in the paths of an XML patch
+func (pb *PatchBuilder) Namespace(prefix string, uri string) *PatchBuilder {
+	pb.namespaces[prefix] = uri
+	return pb
+}
+
+// Insert adds content before, after or as the last child of the nodes
+// matching context. For XML patches content may be a string of XML, a
+// *handle.XMLNode or a value to marshal; for JSON patches it is marshalled.
+func (pb *PatchBuilder) Insert(context string, position string, content interface{}) *PatchBuilder {
+	return pb.add(&patchOperation{kind: "insert", context: context, position: position, content: content, hasContent: true})
+}
+
+// Replace replaces the nodes matching selectPath with content
+func (pb *PatchBuilder) Replace(selectPath string, content interface{}) *PatchBuilder {
+	return pb.add(&patchOperation{kind: "replace", selectPath: selectPath, content: content, hasContent: true})
+}
+
+// ReplaceApply replaces the nodes matching selectPath with the result of a
+// replacement function such as ml.add or ml.concat-after applied to content
+func (pb *PatchBuilder) ReplaceApply(selectPath string, apply string, content interface{}) *PatchBuilder {
+	return pb.add(&patchOperation{kind: "replace", selectPath: selectPath, apply: apply, content: content, hasContent: content != nil})
+}
+
+// ReplaceInsert replaces the nodes matching selectPath with content, or
+// inserts content at position relative to context when nothing matches
+func (pb *PatchBuilder) ReplaceInsert(selectPath string, context string, position string, content interface{}) *PatchBuilder {
+	return pb.add(&patchOperation{kind: "replace-insert", selectPath: selectPath, context: context, position: position, content: content, hasContent: true})
+}
+
+// Delete removes the nodes matching selectPath
+func (pb *PatchBuilder) Delete(selectPath string) *PatchBuilder {
+	return pb.add(&patchOperation{kind: "delete", selectPath: selectPath})
+}
+
+// AddCollections adds the document to collections
+func (pb *PatchBuilder) AddCollections(collections ...string) *PatchBuilder {
+	for _, collection := range collections {
+		if pb.format == handle.XML {
+			pb.addMetadata(&patchOperation{kind: "insert", context: "/rapi:metadata/rapi:collections", position: PatchLastChild,
+				content: "<rapi:collection>" + escapeXML(collection) + "</rapi:collection>"})
+		} else {
+			pb.addMetadata(&patchOperation{kind: "insert", context: "/array-node('collections')", position: PatchLastChild, content: collection})
+		}
+	}
+	return pb
+}
+
+// RemoveCollections removes the document from collections
+func (pb *PatchBuilder) RemoveCollections(collections ...string) *PatchBuilder {
+	for _, collection := range collections {
+		if pb.format == handle.XML {
+			pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/rapi:metadata/rapi:collections/rapi:collection[. = " + xpathLiteral(collection) + "]"})
+		} else {
+			pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/collections[. = " + xpathLiteral(collection) + "]"})
+		}
+	}
+	return pb
+}
+
+// AddPermission grants capabilities (read, update, insert, execute) to a role
+func (pb *PatchBuilder) AddPermission(roleName string, capabilities ...string) *PatchBuilder {
+	if pb.format == handle.XML {
+		content := "<rapi:permission><rapi:role-name>" + escapeXML(roleName) + "</rapi:role-name>"
+		for _, capability := range capabilities {
+			content = content + "<rapi:capability>" + escapeXML(capability) + "</rapi:capability>"
+		}
+		content = content + "</rapi:permission>"
+		return pb.addMetadata(&patchOperation{kind: "insert", context: "/rapi:metadata/rapi:permissions", position: PatchLastChild, content: content})
+	}
+	return pb.addMetadata(&patchOperation{kind: "insert", context: "/array-node('permissions')", position: PatchLastChild,
+		content: Permission{RoleName: roleName, Capability: capabilities}})
+}
+
+// RemovePermission removes all permissions granted to a role
+func (pb *PatchBuilder) RemovePermission(roleName string) *PatchBuilder {
+	if pb.format == handle.XML {
+		return pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/rapi:metadata/rapi:permissions/rapi:permission[rapi:role-name = " + xpathLiteral(roleName) + "]"})
+	}
+	return pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/permissions[role-name = " + xpathLiteral(roleName) + "]"})
+}
+
+// SetProperty adds a document property, replacing any existing value.
+// Property names are XML names without a prefix.
+func (pb *PatchBuilder) SetProperty(name string, value string) *PatchBuilder {
+	if !pb.validProperty(name) {
+		return pb
+	}
+	pb.RemoveProperty(name)
+	if pb.format == handle.XML {
+		return pb.addMetadata(&patchOperation{kind: "insert", context: "/rapi:metadata/prop:properties", position: PatchLastChild,
+			content: "<" + name + ">" + escapeXML(value) + "</" + name + ">"})
+	}
+	return pb.addMetadata(&patchOperation{kind: "insert", context: "/properties", position: PatchLastChild,
+		content: map[string]string{name: value}})
+}
+
+// RemoveProperty removes a document property
+func (pb *PatchBuilder) RemoveProperty(name string) *PatchBuilder {
+	if !pb.validProperty(name) {
+		return pb
+	}
+	if pb.format == handle.XML {
+		return pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/rapi:metadata/prop:properties/" + name})
+	}
+	return pb.addMetadata(&patchOperation{kind: "delete", selectPath: "/properties/node(" + xpathLiteral(name) + ")"})
+}
+
+// SetQuality sets the document quality
+func (pb *PatchBuilder) SetQuality(quality int) *PatchBuilder {
+	if pb.format == handle.XML {
+		return pb.addMetadata(&patchOperation{kind: "replace", selectPath: "/rapi:metadata/rapi:quality",
+			content: "<rapi:quality>" + strconv.Itoa(quality) + "</rapi:quality>"})
+	}
+	return pb.addMetadata(&patchOperation{kind: "replace", selectPath: "/quality", content: quality})
+}
+
+// validProperty records an error for Build when name is not a valid
+// property name
+func (pb *PatchBuilder) validProperty(name string) bool {
+	if util.IsNCName(name) {
+		return true
+	}
+	if pb.err == nil {
+		pb.err = errors.New("invalid property name: " + name)
+	}
+	return false
+}
+
+func (pb *PatchBuilder) add(operation *patchOperation) *PatchBuilder {
+	pb.operations = append(pb.operations, operation)
+	return pb
+}
+
+func (pb *PatchBuilder) addMetadata(operation *patchOperation) *PatchBuilder {
+	operation.metadata = true
+	operation.hasContent = operation.content != nil
+	return pb.add(operation)
+}
+
+// Categories returns the categories the patch applies to (content, metadata
+// or both)
+func (pb *PatchBuilder) Categories() []string {
+	categories := []string{}
+	content, metadata := false, false
+	for _, operation := range pb.operations {
+		if operation.metadata {
+			metadata = true
+		} else {
+			content = true
+		}
+	}
+	if content {
+		categories = append(categories, "content")
+	}
+	if metadata {
+		categories = append(categories, "metadata")
+	}
+	return categories
+}
+
+// Build serializes the patch into a handle that can be sent to the REST API
+func (pb *PatchBuilder) Build() (handle.Handle, error) {
+	if pb.err != nil {
+		return nil, pb.err
+	}
+	if len(pb.operations) == 0 {
+		return nil, errors.New("patch has no operations")
+	}
+	for _, operation := range pb.operations {
+		if operation.kind == "insert" && operation.position == "" {
+			return nil, errors.New("patch insert requires a position")
+		}
+		if operation.selectPath == "" && operation.kind != "insert" {
+			return nil, errors.New("patch " + operation.kind + " requires a select path")
+		}
+		if operation.context == "" && operation.kind != "replace" && operation.kind != "delete" {
+			return nil, errors.New("patch " + operation.kind + " requires a context path")
+		}
+		if operation.position != "" && operation.position != PatchBefore && operation.position != PatchAfter && operation.position != PatchLastChild {
+			return nil, errors.New("invalid patch position: " + operation.position)
+		}
+	}
+	var serialized []byte
+	var err error
+	switch pb.format {
+	case handle.JSON:
+		serialized, err = pb.serializeJSON()
+	case handle.XML:
+		serialized, err = pb.serializeXML()
+	default:
+		err = errors.New("patches can only be applied to JSON or XML documents")
+	}
+	if err != nil {
+		return nil, err
+	}
+	patch := &handle.RawHandle{Format: pb.format}
+	patch.Serialize(serialized)
+	return patch, nil
+}
+
+type jsonPatchOperation struct {
+	Context  string          `json:"context,omitempty"`
+	Select   string          `json:"select,omitempty"`
+	Position string          `json:"position,omitempty"`
+	Apply    string          `json:"apply,omitempty"`
+	Content  json.RawMessage `json:"content,omitempty"`
+}
+
+type jsonPatch struct {
+	PathLang string                          `json:"pathlang,omitempty"`
+	Patch    []map[string]jsonPatchOperation `json:"patch"`
+}
+
+func (pb *PatchBuilder) serializeJSON() ([]byte, error) {
+	patch := jsonPatch{Patch: []map[string]jsonPatchOperation{}}
+	if pb.pathLang != XPathLanguage {
+		if pb.pathLang != JSONPathLanguage {
+			return nil, errors.New("invalid patch path language: " + pb.pathLang)
+		}
+		for _, operation := range pb.operations {
+			if operation.metadata {
+				return nil, errors.New("metadata patches require the xpath path language")
+			}
+		}
+		patch.PathLang = pb.pathLang
+	}
+	for _, operation := range pb.operations {
+		jsonOperation := jsonPatchOperation{
+			Context:  operation.context,
+			Select:   operation.selectPath,
+			Position: operation.position,
+			Apply:    operation.apply,
+		}
+		if operation.hasContent {
+			serialized, err := json.Marshal(operation.content)
+			if err != nil {
+				return nil, err
+			}
+			jsonOperation.Content = serialized
+		}
+		patch.Patch = append(patch.Patch, map[string]jsonPatchOperation{operation.kind: jsonOperation})
+	}
+	return json.Marshal(patch)
+}
+
+func (pb *PatchBuilder) serializeXML() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(`<rapi:patch xmlns:rapi="` + restAPINamespace + `" xmlns:prop="` + propertyNamespace + `"`)
+	prefixes := make([]string, 0, len(pb.namespaces))
+	for prefix := range pb.namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			buffer.WriteString(` xmlns="` + escapeXML(pb.namespaces[prefix]) + `"`)
+		} else {
+			buffer.WriteString(` xmlns:` + prefix + `="` + escapeXML(pb.namespaces[prefix]) + `"`)
+		}
+	}
+	buffer.WriteString(">")
+	for _, operation := range pb.operations {
+		buffer.WriteString("<rapi:" + operation.kind)
+		writeXMLAttr(buffer, "context", operation.context)
+		writeXMLAttr(buffer, "select", operation.selectPath)
+		writeXMLAttr(buffer, "position", operation.position)
+		writeXMLAttr(buffer, "apply", operation.apply)
+		if !operation.hasContent {
+			buffer.WriteString("/>")
+			continue
+		}
+		buffer.WriteString(">")
+		switch content := operation.content.(type) {
+		case string:
+			buffer.WriteString(content)
+		case *handle.XMLNode:
+			buffer.WriteString(content.String())
+		default:
+			serialized, err := xml.Marshal(content)
+			if err != nil {
+				return nil, err
+			}
+			buffer.Write(serialized)
+		}
+		buffer.WriteString("</rapi:" + operation.kind + ">")
+	}
+	buffer.WriteString("</rapi:patch>")
+	return buffer.Bytes(), nil
+}
+
+func writeXMLAttr(buffer *bytes.Buffer, name string, value string) {
+	if value != "" {
+		buffer.WriteString(" " + name + `="` + escapeXML(value) + `"`)
+	}
+}
+
+func escapeXML(value string) string {
+	buffer := &bytes.Buffer{}
+	xml.EscapeText(buffer, []byte(value))
+	return buffer.String()
+}
+
+// xpathLiteral quotes a string for use in an XPath expression
+func xpathLiteral(value string) string {
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+	parts := strings.Split(value, "'")
+	return "concat('" + strings.Join(parts, `', "'", '`) + "')"
+}
+
+func patchDocument(c *clients.Client, uri string, patchBuilder *PatchBuilder, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
+	patchHandle, err := patchBuilder.Build()
+	if err != nil {
+		return err
+	}
+	params := buildParameters([]string{uri}, patchBuilder.Categories(), nil, nil, nil, transform)
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := util.BuildRequestFromHandle(c, "POST", "/documents"+params, patchHandle)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("X-HTTP-Method-Override", http.MethodPatch)
+	return util.Execute(c, req, response)
+}
@@ -0,0 +1,106 @@
+package documents
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func TestPatchBuilderJSON(t *testing.T) {
+	patch, err := NewPatchBuilder(handle.JSON).
+		Insert("/array-node('tags')", PatchLastChild, "new").
+		Replace("/name", "Bob").
+		ReplaceApply("/count", "ml.add", 1).
+		ReplaceInsert("/status", "/", PatchLastChild, map[string]string{"status": "active"}).
+		Delete("/session").
+		AddCollections("users").
+		RemoveCollections("o'neil").
+		SetQuality(2).
+		Build()
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := `{"patch":[` +
+		`{"insert":{"context":"/array-node('tags')","position":"last-child","content":"new"}},` +
+		`{"replace":{"select":"/name","content":"Bob"}},` +
+		`{"replace":{"select":"/count","apply":"ml.add","content":1}},` +
+		`{"replace-insert":{"context":"/","select":"/status","position":"last-child","content":{"status":"active"}}},` +
+		`{"delete":{"select":"/session"}},` +
+		`{"insert":{"context":"/array-node('collections')","position":"last-child","content":"users"}},` +
+		`{"delete":{"select":"/collections[. = \"o'neil\"]"}},` +
+		`{"replace":{"select":"/quality","content":2}}]}`
+	if result := patch.Serialized(); result != want {
+		t.Errorf("Patch Results = %+v, Want = %+v", result, want)
+	}
+}
+
+func TestPatchBuilderXML(t *testing.T) {
+	patch, err := NewPatchBuilder(handle.XML).
+		Namespace("ex", "http://example.com").
+		Insert("/ex:doc", PatchLastChild, "<ex:note>new</ex:note>").
+		Delete("/ex:doc/ex:old").
+		AddPermission("reader", "read").
+		SetProperty("status", "a&b").
+		Build()
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := `<rapi:patch xmlns:rapi="http://marklogic.com/rest-api" xmlns:prop="http://marklogic.com/xdmp/property" xmlns:ex="http://example.com">` +
+		`<rapi:insert context="/ex:doc" position="last-child"><ex:note>new</ex:note></rapi:insert>` +
+		`<rapi:delete select="/ex:doc/ex:old"/>` +
+		`<rapi:insert context="/rapi:metadata/rapi:permissions" position="last-child"><rapi:permission><rapi:role-name>reader</rapi:role-name><rapi:capability>read</rapi:capability></rapi:permission></rapi:insert>` +
+		`<rapi:delete select="/rapi:metadata/prop:properties/status"/>` +
+		`<rapi:insert context="/rapi:metadata/prop:properties" position="last-child"><status>a&amp;b</status></rapi:insert>` +
+		`</rapi:patch>`
+	if result := patch.Serialized(); result != want {
+		t.Errorf("Patch Results = %+v, Want = %+v", result, want)
+	}
+	if _, err := handle.ParseXML([]byte(patch.Serialized())); err != nil {
+		t.Errorf("Patch is not well-formed: %v", err)
+	}
+}
+
+func TestPatchBuilderErrors(t *testing.T) {
+	builders := map[string]*PatchBuilder{
+		"empty":             NewPatchBuilder(handle.JSON),
+		"position":          NewPatchBuilder(handle.JSON).Insert("/a", "first-child", 1),
+		"jsonpath":          NewPatchBuilder(handle.JSON).PathLanguage(JSONPathLanguage).AddCollections("a"),
+		"format":            NewPatchBuilder(handle.TEXTPLAIN).Delete("/a"),
+		"no position":       NewPatchBuilder(handle.JSON).Insert("/a", "", 1),
+		"property":          NewPatchBuilder(handle.XML).SetProperty("my status", "a"),
+		"removed property":  NewPatchBuilder(handle.JSON).RemoveProperty("a/b"),
+		"prefixed property": NewPatchBuilder(handle.XML).RemoveProperty("p:a"),
+	}
+	for name, builder := range builders {
+		if _, err := builder.Build(); err == nil {
+			t.Errorf("Expected error building %s patch", name)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	var request *http.Request
+	var body []byte
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	patch := NewPatchBuilder(handle.JSON).PathLanguage(JSONPathLanguage).Replace("$.name", "Bob")
+	if err := NewService(client).Patch("/a.json", patch, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	result := request.Method + " " + request.URL.RequestURI() + " " + request.Header.Get("X-HTTP-Method-Override") + " " + request.Header.Get("Content-Type")
+	want := "POST /documents?uri=%2Fa.json&category=content PATCH application/json"
+	if result != want {
+		t.Errorf("Patch Request = %+v, Want = %+v", result, want)
+	}
+	wantBody := `{"pathlang":"jsonpath","patch":[{"replace":{"select":"$.name","content":"Bob"}}]}`
+	if string(body) != wantBody {
+		t.Errorf("Patch Body = %+v, Want = %+v", string(body), wantBody)
+	}
+}
@@ -0,0 +1,153 @@
+package documents
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// defaultChunkSize is the size of the ranges requested by Download when none
+// is given
+const defaultChunkSize = 4 * 1024 * 1024
+
+// ContentRange describes the part of a document returned by a range read.
+// End is inclusive and Total is -1 when the server does not report the size
+// of the document.
+type ContentRange struct {
+	Start       int64
+	End         int64
+	Total       int64
+	Length      int64
+	ContentType string
+	VersionID   int
+}
+
+// parseContentRange parses a Content-Range header of the form
+// "bytes start-end/total"
+func parseContentRange(header string, contentRange *ContentRange) bool {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !found {
+		return false
+	}
+	span, total, found := strings.Cut(spec, "/")
+	if !found {
+		return false
+	}
+	start, end, found := strings.Cut(span, "-")
+	if !found {
+		return false
+	}
+	var err error
+	if contentRange.Start, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return false
+	}
+	if contentRange.End, err = strconv.ParseInt(end, 10, 64); err != nil {
+		return false
+	}
+	contentRange.Total = -1
+	if total != "*" {
+		contentRange.Total, _ = strconv.ParseInt(total, 10, 64)
+	}
+	return true
+}
+
+func readRange(c *clients.Client, uri string, start int64, end int64, versionID int, transaction *util.Transaction, writer io.Writer) (*ContentRange, error) {
+	if start < 0 || (end >= 0 && end < start) {
+		return nil, errors.New("invalid byte range")
+	}
+	params := buildParameters([]string{uri}, []string{"content"}, nil, nil, nil, nil)
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("GET", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+	rangeHeader := "bytes=" + strconv.FormatInt(start, 10) + "-"
+	limit := int64(-1)
+	if end >= 0 {
+		rangeHeader = rangeHeader + strconv.FormatInt(end, 10)
+		limit = end - start + 1
+	}
+	req.Header.Add("Range", rangeHeader)
+	req.Header.Add("Accept", "*/*")
+	response := &rangeResponseHandle{StreamHandle: &handle.StreamHandle{Writer: writer, Format: handle.BINARY}, uri: uri, versionID: versionID, start: start, limit: limit}
+	if err := util.Execute(c, req, response); err != nil {
+		return nil, err
+	}
+	contentRange := &ContentRange{
+		Start:       start,
+		Total:       -1,
+		Length:      response.Written,
+		ContentType: response.Header.Get("Content-Type"),
+		VersionID:   versionIDFromETag(response.Header.Get("ETag")),
+	}
+	if response.StatusCode != http.StatusPartialContent || !parseContentRange(response.Header.Get("Content-Range"), contentRange) {
+		contentRange.Total, err = strconv.ParseInt(response.Header.Get("Content-Length"), 10, 64)
+		if err != nil {
+			contentRange.Total = -1
+		}
+	}
+	contentRange.End = contentRange.Start + contentRange.Length - 1
+	return contentRange, nil
+}
+
+// rangeResponseHandle streams a range read, trimming the content itself when
+// the server answers with the whole document instead of the range. When
+// versionID is set nothing is written unless the document is at that version.
+type rangeResponseHandle struct {
+	*handle.StreamHandle
+	uri       string
+	versionID int
+	start     int64
+	limit     int64
+}
+
+// AcceptResponse handles an *http.Response
+func (rh *rangeResponseHandle) AcceptResponse(resp *http.Response) error {
+	if current := versionIDFromETag(resp.Header.Get("ETag")); rh.versionID != 0 && current != 0 && current != rh.versionID {
+		resp.Body.Close()
+		return &VersionConflictError{URI: rh.uri, VersionID: rh.versionID}
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		if _, err := io.CopyN(io.Discard, resp.Body, rh.start); err != nil && err != io.EOF {
+			resp.Body.Close()
+			return err
+		}
+		if rh.limit >= 0 {
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(resp.Body, rh.limit), resp.Body}
+		}
+	}
+	return rh.StreamHandle.AcceptResponse(resp)
+}
+
+func download(c *clients.Client, uri string, writer io.Writer, offset int64, chunkSize int64, transaction *util.Transaction) (int64, error) {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	var written int64
+	versionID := 0
+	for {
+		contentRange, err := readRange(c, uri, offset+written, offset+written+chunkSize-1, versionID, transaction, writer)
+		if err != nil {
+			var statusError *util.StatusError
+			if errors.As(err, &statusError) && statusError.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+				return written, nil
+			}
+			return written, err
+		}
+		written += contentRange.Length
+		versionID = contentRange.VersionID
+		if contentRange.Length < chunkSize || (contentRange.Total >= 0 && offset+written >= contentRange.Total) {
+			return written, nil
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+var rangeContent = []byte("0123456789abcdefghij")
+
+// rangeHandler serves rangeContent honouring Range headers unless ignoreRange
+// is set, reporting the version returned by version for each request
+func rangeHandler(ignoreRange bool, version func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("ETag", `"`+version()+`"`)
+		spec, found := strings.CutPrefix(r.Header.Get("Range"), "bytes=")
+		if ignoreRange || !found {
+			w.Write(rangeContent)
+			return
+		}
+		startText, endText, _ := strings.Cut(spec, "-")
+		start, _ := strconv.Atoi(startText)
+		end := len(rangeContent) - 1
+		if endText != "" {
+			end, _ = strconv.Atoi(endText)
+		}
+		if start >= len(rangeContent) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		if end >= len(rangeContent) {
+			end = len(rangeContent) - 1
+		}
+		w.Header().Set("Content-Range", "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(end)+"/"+strconv.Itoa(len(rangeContent)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(rangeContent[start : end+1])
+	})
+}
+
+func TestReadRange(t *testing.T) {
+	for _, ignoreRange := range []bool{false, true} {
+		client, server := test.ClientWithHandler(rangeHandler(ignoreRange, func() string { return "7" }))
+		buffer := &bytes.Buffer{}
+		contentRange, err := NewService(client).ReadRange("/video.mp4", 5, 9, nil, buffer)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Error = %v", err)
+		}
+		want := ContentRange{Start: 5, End: 9, Total: 20, Length: 5, ContentType: "application/pdf", VersionID: 7}
+		if *contentRange != want || buffer.String() != "56789" {
+			t.Errorf("Range Results = %+v %q, Want = %+v %q", *contentRange, buffer.String(), want, "56789")
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	for _, ignoreRange := range []bool{false, true} {
+		client, server := test.ClientWithHandler(rangeHandler(ignoreRange, func() string { return "7" }))
+		buffer := &bytes.Buffer{}
+		written, err := NewService(client).Download("/video.mp4", buffer, 0, 6, nil)
+		if err != nil || written != 20 || buffer.String() != string(rangeContent) {
+			t.Errorf("Download Results = %v %q %v, Want = %v %q", written, buffer.String(), err, 20, rangeContent)
+		}
+		buffer.Reset()
+		written, err = NewService(client).Download("/video.mp4", buffer, 12, 5, nil)
+		if err != nil || written != 8 || buffer.String() != string(rangeContent[12:]) {
+			t.Errorf("Resumed Download Results = %v %q %v, Want = %v %q", written, buffer.String(), err, 8, rangeContent[12:])
+		}
+		server.Close()
+	}
+}
+
+func TestDownloadVersionChange(t *testing.T) {
+	requests := 0
+	client, server := test.ClientWithHandler(rangeHandler(false, func() string {
+		requests++
+		return strconv.Itoa(requests)
+	}))
+	defer server.Close()
+	buffer := &bytes.Buffer{}
+	written, err := NewService(client).Download("/video.mp4", buffer, 0, 6, nil)
+	if !errors.Is(err, ErrVersionConflict) || written != 6 || buffer.String() != "012345" {
+		t.Errorf("Download Results = %v %q %v, Want = %v %q %v", written, buffer.String(), err, 6, "012345", ErrVersionConflict)
+	}
+}
@@ -0,0 +1,103 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// descriptionsHandle reads the parts of a multi-document read into
+// DocumentDescriptions keyed by URI
+type descriptionsHandle struct {
+	*handle.RawHandle
+	uris         []string
+	descriptions map[string]*DocumentDescription
+}
+
+func (dh *descriptionsHandle) description(uri string) *DocumentDescription {
+	description, ok := dh.descriptions[uri]
+	if !ok {
+		description = &DocumentDescription{URI: uri, Metadata: &Metadata{}}
+		dh.descriptions[uri] = description
+	}
+	return description
+}
+
+// AcceptResponse handles an *http.Response
+func (dh *descriptionsHandle) AcceptResponse(resp *http.Response) error {
+	dh.SetTimestamp(resp.Header.Get("ML-Effective-Timestamp"))
+	return eachPart(resp, func(header textproto.MIMEHeader, disposition map[string]string, content []byte) error {
+		uri := disposition["filename"]
+		if uri == "" && len(dh.uris) == 1 {
+			uri = dh.uris[0]
+		}
+		description := dh.description(uri)
+		contentType := header.Get("Content-Type")
+		if disposition["category"] == "metadata" {
+			if handle.MimeTypeToFormatEnum(contentType) == handle.XML {
+				return xml.Unmarshal(content, description.Metadata)
+			}
+			return json.Unmarshal(content, description.Metadata)
+		}
+		description.Content = bytes.NewBuffer(content)
+		description.MimeType = contentType
+		description.Format = handle.DocumentFormatToFormatEnum(disposition["format"])
+		if description.Format == handle.UNKNOWN {
+			description.Format = handle.DocumentFormatToFormatEnum(header.Get("vnd.marklogic.document-format"))
+		}
+		if description.Format == handle.UNKNOWN {
+			description.Format = handle.MimeTypeToFormatEnum(contentType)
+		}
+		if versionID, err := strconv.Atoi(disposition["versionid"]); err == nil {
+			description.VersionID = versionID
+		} else {
+			description.VersionID = versionIDFromETag(header.Get("ETag"))
+		}
+		return nil
+	})
+}
+
+func readDocuments(c *clients.Client, uris []string, categories []string, codec Codec, transform *util.Transform, transaction *util.Transaction) ([]*DocumentDescription, error) {
+	if len(uris) == 0 {
+		return nil, errors.New("no document URIs to read")
+	}
+	if codec != nil {
+		categories = decodeCategories(categories)
+	}
+	if len(categories) == 0 {
+		categories = []string{"content", "metadata"}
+	}
+	params := buildParameters(uris, categories, nil, nil, nil, transform) + "&format=json"
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("GET", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", handle.FormatEnumToMimeType(handle.MIXED))
+	response := &descriptionsHandle{RawHandle: &handle.RawHandle{Format: handle.MIXED}, uris: uris, descriptions: map[string]*DocumentDescription{}}
+	if err := util.Execute(c, req, response); err != nil {
+		return nil, err
+	}
+	descriptions := make([]*DocumentDescription, 0, len(response.descriptions))
+	for _, uri := range uris {
+		if description, ok := response.descriptions[uri]; ok {
+			if codec != nil && description.Content != nil {
+				if err := codec.Decode(description); err != nil {
+					return nil, err
+				}
+			}
+			descriptions = append(descriptions, description)
+			delete(response.descriptions, uri)
+		}
+	}
+	return descriptions, nil
+}
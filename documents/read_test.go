@@ -0,0 +1,50 @@
+package documents
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func TestReadDocuments(t *testing.T) {
+	var request *http.Request
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		writer := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+		parts := [][3]string{
+			{"application/json", `attachment; filename="/b.xml"; category=metadata; format=json`, `{"collections":["b"]}`},
+			{"application/xml", `attachment; filename="/b.xml"; category=content; format=xml; versionId=22`, `<b/>`},
+			{"application/json", `attachment; filename="/a.json"; category=metadata; format=json`, `{"collections":["a"],"quality":2}`},
+			{"application/json", `attachment; filename="/a.json"; category=content; format=json; versionId=11`, `{"a":1}`},
+		}
+		for _, p := range parts {
+			part, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {p[0]}, "Content-Disposition": {p[1]}})
+			part.Write([]byte(p[2]))
+		}
+		writer.Close()
+	}))
+	defer server.Close()
+	docs, err := NewService(client).ReadDocuments([]string{"/a.json", "/missing.json", "/b.xml"}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := []*DocumentDescription{
+		{URI: "/a.json", Content: bytes.NewBufferString(`{"a":1}`), Metadata: &Metadata{Collections: []string{"a"}, Quality: 2}, Format: handle.JSON, MimeType: "application/json", VersionID: 11},
+		{URI: "/b.xml", Content: bytes.NewBufferString(`<b/>`), Metadata: &Metadata{Collections: []string{"b"}}, Format: handle.XML, MimeType: "application/xml", VersionID: 22},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("Read Results = %+v, Want = %+v", spew.Sdump(docs), spew.Sdump(want))
+	}
+	wantRequest := "/documents?uri=%2Fa.json&uri=%2Fmissing.json&uri=%2Fb.xml&category=content&category=metadata&format=json"
+	if request.URL.RequestURI() != wantRequest || request.Header.Get("Accept") != "multipart/mixed" {
+		t.Errorf("Read Request = %+v, Want = %+v", request.URL.RequestURI(), wantRequest)
+	}
+}
@@ -21,10 +21,12 @@
 //	        Collections: []string{"users", "active"},
 //	    },
 //	}
-//	client.Documents().Write([]*documents.DocumentDescription{doc}, nil, nil, respHandle)
+//	results, err := client.Documents().WriteConcurrent([]*documents.DocumentDescription{doc}, 0, nil, nil)
 package documents
 
 import (
+	"io"
+
 	"github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
 	"github.com/ryanjdew/go-marklogic-go/util"
@@ -35,6 +37,7 @@ import (
 // transparent format negotiation based on handle types.
 type Service struct {
 	client *clients.Client
+	codec  Codec
 }
 
 // NewService creates and returns a new documents.Service instance configured
@@ -68,10 +71,101 @@ func (s *Service) Read(uris []string, categories []string, transform *util.Trans
 	return read(s.client, uris, categories, transform, transaction, response)
 }
 
+// ReadDocument retrieves the content of a single document into a
+// DocumentDescription with its Format, MimeType and, when the content
+// versioning policy is enabled, VersionID populated from the response headers.
+// The result can be modified and passed back to Write for an optimistic
+// read-modify-write cycle.
+//
+// Parameters:
+//
+//	uri: Document URI to read
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction for consistent reads
+func (s *Service) ReadDocument(uri string, transform *util.Transform, transaction *util.Transaction) (*DocumentDescription, error) {
+	return readDocument(s.client, uri, s.codec, transform, transaction)
+}
+
+// ReadDocuments retrieves documents with their metadata as
+// DocumentDescriptions with URI, Format, MimeType, Content, VersionID and
+// Metadata populated, ready to be passed to Write, WriteSet or a WriteBatcher.
+// Results follow the order of uris; documents that do not exist are omitted.
+//
+// Parameters:
+//
+//	uris: Document URIs to read
+//	categories: Categories to read (nil for content and all metadata)
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction for consistent reads
+func (s *Service) ReadDocuments(uris []string, categories []string, transform *util.Transform, transaction *util.Transaction) ([]*DocumentDescription, error) {
+	return readDocuments(s.client, uris, categories, s.codec, transform, transaction)
+}
+
+// ReadRange streams bytes start through end (inclusive) of a document's
+// content to writer using a Range request; an end below 0 reads to the end of
+// the document. The returned ContentRange reports the range received and the
+// total size of the document.
+//
+// Parameters:
+//
+//	uri: Document URI to read
+//	start: Offset of the first byte to read
+//	end: Offset of the last byte to read (-1 for the end of the document)
+//	transaction: Optional transaction for consistent reads
+//	writer: Destination for the content
+func (s *Service) ReadRange(uri string, start int64, end int64, transaction *util.Transaction, writer io.Writer) (*ContentRange, error) {
+	return readRange(s.client, uri, start, end, 0, transaction, writer)
+}
+
+// Download streams a document's content to writer in chunks of chunkSize
+// bytes, starting at offset. It returns the number of bytes written, so an
+// interrupted download can be resumed by calling Download again with offset
+// advanced by that amount. A *VersionConflictError is returned, before any
+// of its content is written, if the document changes between chunks.
+//
+// Parameters:
+//
+//	uri: Document URI to download
+//	writer: Destination for the content
+//	offset: Offset to start (or resume) the download from
+//	chunkSize: Bytes requested per request (0 for the default of 4MB)
+//	transaction: Optional transaction for consistent reads
+func (s *Service) Download(uri string, writer io.Writer, offset int64, chunkSize int64, transaction *util.Transaction) (int64, error) {
+	return download(s.client, uri, writer, offset, chunkSize, transaction)
+}
+
+// ReadMetadata retrieves only the metadata of one or more documents, keyed
+// by URI. Categories limit what is returned (collections, permissions,
+// properties, quality, metadata-values); nil returns all metadata.
+//
+// Parameters:
+//
+//	uris: Document URIs to read metadata for
+//	categories: Metadata categories to read (nil for all)
+//	transaction: Optional transaction for consistent reads
+func (s *Service) ReadMetadata(uris []string, categories []string, transaction *util.Transaction) (map[string]*Metadata, error) {
+	return readMetadata(s.client, uris, categories, transaction)
+}
+
+// WriteMetadata replaces the metadata of existing documents without touching
+// their content, issuing at most concurrency requests at a time. Only the
+// given categories are replaced; nil replaces all metadata.
+//
+// Parameters:
+//
+//	metadata: Metadata to write keyed by document URI
+//	categories: Metadata categories to write (nil for all)
+//	concurrency: Maximum simultaneous requests (0 for the default of 8)
+//	transaction: Optional transaction
+func (s *Service) WriteMetadata(metadata map[string]*Metadata, categories []string, concurrency int, transaction *util.Transaction) error {
+	return writeMetadata(s.client, metadata, categories, concurrency, transaction)
+}
+
 // Write creates or updates documents. Operations are executed concurrently per
 // document for efficiency. Supports metadata (collections, permissions, properties)
 // and server-side transformations. Returns successfully if all documents write succeed;
-// returns error if any document fails.
+// returns error if any document fails. Use WriteConcurrent to bound the number of
+// requests and get a result per document.
 //
 // Parameters:
 //
@@ -80,20 +174,148 @@ func (s *Service) Read(uris []string, categories []string, transform *util.Trans
 //	transaction: Optional transaction for atomic multi-document updates
 //	response: ResponseHandle for results
 func (s *Service) Write(documents []*DocumentDescription, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
-	return write(s.client, documents, transform, transaction, response)
+	results, err := write(s.client, documents, 0, s.codec, transform, transaction)
+	if response != nil {
+		for _, result := range results {
+			if result.Response != nil {
+				response.Deserialize(result.Response.Bytes())
+			}
+		}
+	}
+	return err
+}
+
+// WriteConcurrent creates or updates documents with one PUT per document,
+// issuing at most concurrency requests at a time. Supports metadata
+// (collections, permissions, properties) and server-side transformations. A
+// result is returned for every document, in the same order as documents; the
+// error joins the errors of the documents that failed. Documents with a
+// VersionID are only written if unchanged on the server; otherwise their
+// result holds a *VersionConflictError.
+//
+// Parameters:
+//
+//	documents: DocumentDescription slice with URI, content, and metadata
+//	concurrency: Maximum simultaneous requests (0 for the default of 8)
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction for atomic multi-document updates
+func (s *Service) WriteConcurrent(documents []*DocumentDescription, concurrency int, transform *util.Transform, transaction *util.Transaction) ([]*WriteResult, error) {
+	return write(s.client, documents, concurrency, s.codec, transform, transaction)
+}
+
+// Create inserts a document under a URI generated by the server and sets
+// doc.URI to the assigned URI, which is also returned in the result.
+//
+// Parameters:
+//
+//	doc: DocumentDescription with content and metadata (URI is ignored)
+//	directory: Directory prefix for the generated URI ("" for none)
+//	extension: URI extension ("" to use the one for the document format)
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction
+func (s *Service) Create(doc *DocumentDescription, directory string, extension string, transform *util.Transform, transaction *util.Transaction) (*WriteResult, error) {
+	return create(s.client, doc, directory, extension, s.codec, transform, transaction)
+}
+
+// Patch partially updates a single document's content and/or metadata
+// without rewriting the whole document. The patch is sent as a POST with
+// X-HTTP-Method-Override: PATCH.
+//
+// Parameters:
+//
+//	uri: Document URI to update
+//	patch: PatchBuilder describing the insert/replace/delete operations
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction
+//	response: ResponseHandle for results (may be nil)
+func (s *Service) Patch(uri string, patch *PatchBuilder, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
+	return patchDocument(s.client, uri, patch, transform, transaction, response)
 }
 
 // WriteSet writes multiple documents in a single multipart/mixed request.
-// Provides efficient bulk writing with shared metadata. All documents use
-// the same metadata instance and are sent as a single multipart message.
+// Provides efficient bulk writing with shared metadata: metadata is the
+// default for documents without their own Metadata, and DefaultMetadata
+// entries in documents change the default for the documents after them.
+// Use WriteSetWithResult to get the documents written or the error of each.
 //
 // Parameters:
 //
 //	documents: DocumentDescription slice to write
-//	metadata: Metadata handle applied to all documents
+//	metadata: Default metadata handle (nil for the system defaults)
 //	transform: Optional server-side transformation
 //	transaction: Optional transaction
 //	response: ResponseHandle for results
 func (s *Service) WriteSet(documents []*DocumentDescription, metadata handle.Handle, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) error {
-	return writeSet(s.client, documents, metadata, transform, transaction, response)
+	_, err := writeSet(s.client, documents, metadata, s.codec, transform, transaction, response)
+	return err
+}
+
+// WriteSetWithResult writes documents like WriteSet and describes the
+// outcome. The request is atomic: the result lists every document written
+// with its MIME type, or, when the request fails, every document of the batch
+// with the error that prevented it from being written.
+//
+// Parameters:
+//
+//	documents: DocumentDescription slice to write
+//	metadata: Default metadata handle (nil for the system defaults)
+//	transform: Optional server-side transformation
+//	transaction: Optional transaction
+//	response: ResponseHandle for the raw results (may be nil)
+func (s *Service) WriteSetWithResult(documents []*DocumentDescription, metadata handle.Handle, transform *util.Transform, transaction *util.Transaction, response handle.ResponseHandle) (*WriteSetResult, error) {
+	return writeSet(s.client, documents, metadata, s.codec, transform, transaction, response)
+}
+
+// Delete removes documents by URI. When categories name metadata categories
+// (metadata, collections, permissions, properties, quality, metadata-values)
+// only that metadata is reset to its defaults and the content is kept.
+//
+// Parameters:
+//
+//	uris: Document URIs to delete
+//	categories: Metadata categories to reset (nil to delete the documents)
+//	temporalCollection: Temporal collection the documents belong to ("" if none)
+//	transaction: Optional transaction
+//	response: ResponseHandle for results (may be nil)
+func (s *Service) Delete(uris []string, categories []string, temporalCollection string, transaction *util.Transaction, response handle.ResponseHandle) error {
+	return deleteDocuments(s.client, uris, categories, temporalCollection, 0, transaction, response)
+}
+
+// DeleteIfVersion removes a document only when its current version matches
+// versionID. Requires the content versioning policy to be enabled on the
+// REST instance.
+//
+// Parameters:
+//
+//	uri: Document URI to delete
+//	versionID: Version the document is expected to be at
+//	temporalCollection: Temporal collection the document belongs to ("" if none)
+//	transaction: Optional transaction
+//	response: ResponseHandle for results (may be nil)
+func (s *Service) DeleteIfVersion(uri string, versionID int, temporalCollection string, transaction *util.Transaction, response handle.ResponseHandle) error {
+	return deleteDocuments(s.client, []string{uri}, nil, temporalCollection, versionID, transaction, response)
+}
+
+// Exists checks whether a document exists with a HEAD request and reports its
+// content type, length, format and version without downloading it.
+//
+// Parameters:
+//
+//	uri: Document URI to check
+//	transaction: Optional transaction
+func (s *Service) Exists(uri string, transaction *util.Transaction) (*DocumentInfo, error) {
+	return head(s.client, uri, transaction)
+}
+
+// ExistsAll checks many documents, issuing at most concurrency HEAD requests
+// at a time. Results are in the same order as uris; when a request fails its
+// entry is nil and the first error is returned.
+//
+// Parameters:
+//
+//	uris: Document URIs to check
+//	concurrency: Maximum simultaneous requests (0 for the default of 8)
+//	transaction: Optional transaction
+func (s *Service) ExistsAll(uris []string, concurrency int, transaction *util.Transaction) ([]*DocumentInfo, error) {
+	return headAll(s.client, uris, concurrency, transaction)
 }
@@ -0,0 +1,141 @@
+package documents
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// URITemplate generates URIs of the form Prefix + UUID + Suffix for documents
+// written without a URI. When Suffix is empty the extension for the
+// document's format (.json, .xml or .txt) is used, detected from the content
+// when a written document sets neither Format nor MimeType.
+type URITemplate struct {
+	Prefix string
+	Suffix string
+}
+
+// URI returns a new URI for the document
+func (ut *URITemplate) URI(doc *DocumentDescription) string {
+	suffix := ut.Suffix
+	if suffix == "" {
+		if extension := formatExtension(doc.Format); extension != "" {
+			suffix = "." + extension
+		}
+	}
+	return ut.Prefix + newUUID() + suffix
+}
+
+// formatExtension returns the usual URI extension for a format enum
+func formatExtension(format int) string {
+	switch format {
+	case handle.JSON:
+		return "json"
+	case handle.XML:
+		return "xml"
+	case handle.TEXTPLAIN:
+		return "txt"
+	}
+	return ""
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	uuid := make([]byte, 16)
+	rand.Read(uuid)
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	encoded := hex.EncodeToString(uuid)
+	return encoded[0:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
+
+// assignURI gives a document without a URI one from its URITemplate. When
+// neither the template nor the document decides the extension, it comes from
+// the format detected in the leading bytes of content.
+func assignURI(doc *DocumentDescription, content []byte) error {
+	if doc.URI != "" {
+		return nil
+	}
+	if doc.URITemplate == nil {
+		return errors.New("document has neither a URI nor a URI template")
+	}
+	detected := *doc
+	if doc.MimeType != "" {
+		detected.Format = handle.MimeTypeToFormatEnum(doc.MimeType)
+	} else if doc.URITemplate.Suffix == "" && doc.Format == handle.JSON {
+		detected.Format = handle.MimeTypeToFormatEnum(handle.DetectMimeType("", content))
+	}
+	doc.URI = doc.URITemplate.URI(&detected)
+	return nil
+}
+
+// locationURI extracts the document URI from the Location header returned
+// for a created document
+func locationURI(location string) string {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+	return parsed.Query().Get("uri")
+}
+
+func create(c *clients.Client, doc *DocumentDescription, directory string, extension string, codec Codec, transform *util.Transform, transaction *util.Transaction) (*WriteResult, error) {
+	encoded := doc
+	if codec != nil {
+		var err error
+		if encoded, err = encodeDocument(codec, doc, nil); err != nil {
+			return nil, err
+		}
+	}
+	metadata := encoded.Metadata
+	if metadata == nil {
+		metadata = &Metadata{}
+	}
+	contentType, body := contentTypeAndBody(encoded)
+	if extension == "" {
+		extension = formatExtension(encoded.Format)
+	}
+	if extension == "" {
+		return nil, errors.New("an extension is required for server generated URIs")
+	}
+	params := buildParameters(nil, nil, metadata.Collections, metadata.PermissionsMap(), metadata.Properties, transform)
+	params = util.MappedParameters(params, "value", metadata.MetadataValues)
+	params = util.RepeatingParameters(params, "extension", []string{strings.TrimPrefix(extension, ".")})
+	if directory != "" {
+		params = util.RepeatingParameters(params, "directory", []string{directory})
+	}
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("POST", c.Base()+"/documents"+params, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", contentType)
+	result := &WriteResult{}
+	response := &createResponseHandle{writeResponseHandle{RawHandle: &handle.RawHandle{Format: handle.JSON}, result: result}}
+	result.Response = response.RawHandle
+	if err := util.Execute(c, req, response); err != nil {
+		result.Err = err
+		return result, err
+	}
+	doc.URI = result.URI
+	return result, nil
+}
+
+// createResponseHandle captures the URI assigned to a created document
+type createResponseHandle struct {
+	writeResponseHandle
+}
+
+// AcceptResponse handles an *http.Response
+func (ch *createResponseHandle) AcceptResponse(resp *http.Response) error {
+	ch.result.URI = locationURI(resp.Header.Get("Location"))
+	return ch.writeResponseHandle.AcceptResponse(resp)
+}
@@ -0,0 +1,150 @@
+package documents
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+var uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`
+
+func TestURITemplate(t *testing.T) {
+	cases := map[string]*DocumentDescription{
+		`^/events/` + uuidPattern + `\.json$`: {Format: handle.JSON},
+		`^/events/` + uuidPattern + `\.xml$`:  {Format: handle.XML},
+		`^/events/` + uuidPattern + `$`:       {Format: handle.BINARY},
+	}
+	template := &URITemplate{Prefix: "/events/"}
+	for want, doc := range cases {
+		if result := template.URI(doc); !regexp.MustCompile(want).MatchString(result) {
+			t.Errorf("URI Results = %+v, Want = %+v", result, want)
+		}
+	}
+	custom := &URITemplate{Prefix: "/a/", Suffix: ".data"}
+	if result := custom.URI(&DocumentDescription{}); !regexp.MustCompile(`^/a/` + uuidPattern + `\.data$`).MatchString(result) {
+		t.Errorf("URI Results = %+v, Want = %+v", result, "/a/<uuid>.data")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var request *http.Request
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		io.ReadAll(r.Body)
+		w.Header().Set("Location", "/v1/documents?uri=%2Fevents%2F1234.xml")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	doc := &DocumentDescription{Content: bytes.NewBufferString("<event/>"), Metadata: &Metadata{Collections: []string{"events"}}}
+	result, err := NewService(client).Create(doc, "/events/", "", nil, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if result.URI != "/events/1234.xml" || doc.URI != "/events/1234.xml" {
+		t.Errorf("Create Results = %+v, Want = %+v", result.URI, "/events/1234.xml")
+	}
+	want := "POST /documents?collection=events&extension=xml&directory=%2Fevents%2F application/xml"
+	if got := request.Method + " " + request.URL.RequestURI() + " " + request.Header.Get("Content-Type"); got != want {
+		t.Errorf("Create Request = %+v, Want = %+v", got, want)
+	}
+}
+
+func TestWriteSetURITemplate(t *testing.T) {
+	var parts []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts = multipartParts(r)
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	template := &URITemplate{Prefix: "/events/"}
+	docs := []*DocumentDescription{
+		{URITemplate: template, Content: bytes.NewBufferString(`{"a":1}`), Metadata: &Metadata{Quality: 1}},
+	}
+	if err := NewService(client).WriteSet(docs, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if !regexp.MustCompile(`^/events/` + uuidPattern + `\.json$`).MatchString(docs[0].URI) {
+		t.Errorf("URI Results = %+v, Want = %+v", docs[0].URI, "/events/<uuid>.json")
+	}
+	want := []string{
+		`inline; category=metadata; filename="` + docs[0].URI + `" {"quality":1}`,
+		`attachment; filename="` + docs[0].URI + `" {"a":1}`,
+	}
+	if len(parts) != 2 || parts[0] != want[0] || parts[1] != want[1] {
+		t.Errorf("WriteSet Parts = %+v, Want = %+v", parts, want)
+	}
+	missing := []*DocumentDescription{{Content: bytes.NewBufferString(`{}`)}}
+	if err := NewService(client).WriteSet(missing, nil, nil, nil, nil); err == nil {
+		t.Errorf("Expected error writing a document without a URI")
+	}
+}
+
+func TestURITemplateContentType(t *testing.T) {
+	var contentTypes []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		} else {
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			reader := multipart.NewReader(r.Body, params["boundary"])
+			for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+				contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+			}
+		}
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	// the extension of the template, not the content, sets the content type
+	template := &URITemplate{Prefix: "/notes/", Suffix: ".txt"}
+	doc := &DocumentDescription{URITemplate: template, Content: bytes.NewBufferString(`{"a":1}`)}
+	if result := writeDocument(client, doc, nil, nil, nil); result.Err != nil {
+		t.Fatalf("Error = %v", result.Err)
+	}
+	docs := []*DocumentDescription{{URITemplate: template, Content: bytes.NewBufferString(`{"a":1}`)}}
+	if err := NewService(client).WriteSet(docs, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if want := []string{"text/plain", "text/plain"}; !reflect.DeepEqual(contentTypes, want) {
+		t.Errorf("Content-Type Results = %+v, Want = %+v", contentTypes, want)
+	}
+}
+
+func TestURITemplateDetectedFormat(t *testing.T) {
+	var requests []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			requests = append(requests, r.URL.Query().Get("uri")+" "+r.Header.Get("Content-Type"))
+		} else {
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			reader := multipart.NewReader(r.Body, params["boundary"])
+			for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+				_, disposition, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+				requests = append(requests, disposition["filename"]+" "+part.Header.Get("Content-Type"))
+			}
+		}
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	// without a Format or a Suffix the extension comes from the content
+	template := &URITemplate{Prefix: "/events/"}
+	doc := &DocumentDescription{URITemplate: template, Content: bytes.NewBufferString(`<event/>`)}
+	if result := writeDocument(client, doc, nil, nil, nil); result.Err != nil {
+		t.Fatalf("Error = %v", result.Err)
+	}
+	docs := []*DocumentDescription{{URITemplate: template, Content: bytes.NewBufferString(`<event/>`)}}
+	if err := NewService(client).WriteSet(docs, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := regexp.MustCompile(`^/events/` + uuidPattern + `\.xml application/xml$`)
+	if len(requests) != 2 || !want.MatchString(requests[0]) || !want.MatchString(requests[1]) {
+		t.Errorf("Request Results = %+v, Want = %+v", requests, "/events/<uuid>.xml application/xml")
+	}
+}
@@ -0,0 +1,122 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// ErrVersionConflict is matched by errors.Is when a write or delete is rejected
+// because the document no longer has the expected version
+var ErrVersionConflict = errors.New("document version conflict")
+
+// VersionConflictError reports a write or delete rejected with 412 Precondition
+// Failed because the document changed since VersionID was read. URI is empty
+// when the conflict came from a multi-document request.
+type VersionConflictError struct {
+	URI       string
+	VersionID int
+}
+
+// Error implements the error interface
+func (e *VersionConflictError) Error() string {
+	if e.URI == "" {
+		return ErrVersionConflict.Error()
+	}
+	return ErrVersionConflict.Error() + ": " + e.URI + " is no longer at version " + strconv.Itoa(e.VersionID)
+}
+
+// Is makes errors.Is(err, ErrVersionConflict) true
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// versionConflict converts a 412 response into a VersionConflictError
+func versionConflict(err error, uri string, versionID int) error {
+	var statusError *util.StatusError
+	if errors.As(err, &statusError) && statusError.StatusCode == http.StatusPreconditionFailed {
+		return &VersionConflictError{URI: uri, VersionID: versionID}
+	}
+	return err
+}
+
+// WriteResult reports the outcome of writing a single document. VersionID is
+// the new version reported by the server when content versioning is enabled.
+type WriteResult struct {
+	URI       string
+	VersionID int
+	Err       error
+	Response  *handle.RawHandle
+}
+
+// writeResponseHandle captures the version of a written document
+type writeResponseHandle struct {
+	*handle.RawHandle
+	result *WriteResult
+}
+
+// AcceptResponse handles an *http.Response
+func (wh *writeResponseHandle) AcceptResponse(resp *http.Response) error {
+	if versionID := versionIDFromETag(resp.Header.Get("ETag")); versionID != 0 {
+		wh.result.VersionID = versionID
+	}
+	return handle.CommonHandleAcceptResponse(wh.RawHandle, resp)
+}
+
+// descriptionHandle reads a single document response into a DocumentDescription
+type descriptionHandle struct {
+	*handle.RawHandle
+	description *DocumentDescription
+}
+
+// AcceptResponse handles an *http.Response
+func (dh *descriptionHandle) AcceptResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	dh.Deserialize(content)
+	dh.SetTimestamp(resp.Header.Get("ML-Effective-Timestamp"))
+	dh.description.Content = bytes.NewBuffer(content)
+	dh.description.MimeType = resp.Header.Get("Content-Type")
+	dh.description.Format = handle.DocumentFormatToFormatEnum(resp.Header.Get("vnd.marklogic.document-format"))
+	if dh.description.Format == handle.UNKNOWN {
+		dh.description.Format = handle.MimeTypeToFormatEnum(dh.description.MimeType)
+	}
+	dh.description.VersionID = versionIDFromETag(resp.Header.Get("ETag"))
+	return nil
+}
+
+func readDocument(c *clients.Client, uri string, codec Codec, transform *util.Transform, transaction *util.Transaction) (*DocumentDescription, error) {
+	if codec != nil {
+		descriptions, err := readDocuments(c, []string{uri}, []string{"content", "metadata-values"}, codec, transform, transaction)
+		if err != nil {
+			return nil, err
+		}
+		if len(descriptions) == 0 {
+			return nil, &util.StatusError{StatusCode: http.StatusNotFound}
+		}
+		return descriptions[0], nil
+	}
+	params := buildParameters([]string{uri}, nil, nil, nil, nil, transform)
+	params = util.AddDatabaseParam(params, c)
+	params = util.AddTransactionParam(params, transaction)
+	req, err := http.NewRequest("GET", c.Base()+"/documents"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "*/*")
+	description := &DocumentDescription{URI: uri, Metadata: &Metadata{}}
+	err = util.Execute(c, req, &descriptionHandle{RawHandle: &handle.RawHandle{Format: handle.UNKNOWN}, description: description})
+	if err != nil {
+		return nil, err
+	}
+	return description, nil
+}
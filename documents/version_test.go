@@ -0,0 +1,71 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func versionHandler(current string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Header().Set("ETag", "\""+current+"\"")
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("vnd.marklogic.document-format", "json")
+			w.Write([]byte(`{"a":1}`))
+		default:
+			if match := r.Header.Get("If-Match"); match != "" && match != current {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+func TestReadDocumentVersion(t *testing.T) {
+	client, server := test.ClientWithHandler(versionHandler("1234"))
+	defer server.Close()
+	service := NewService(client)
+	doc, err := service.ReadDocument("/a.json", nil, nil)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if doc.VersionID != 1234 || doc.Format != handle.JSON || doc.MimeType != "application/json" {
+		t.Errorf("Document Results = %+v, Want = %+v", doc, "version 1234 JSON document")
+	}
+	if result := doc.Content.(*bytes.Buffer).String(); result != `{"a":1}` {
+		t.Errorf("Content Results = %+v, Want = %+v", result, `{"a":1}`)
+	}
+	if _, err := service.WriteConcurrent([]*DocumentDescription{doc}, 0, nil, nil); err != nil {
+		t.Errorf("Error = %v", err)
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	client, server := test.ClientWithHandler(versionHandler("5678"))
+	defer server.Close()
+	service := NewService(client)
+	doc := &DocumentDescription{URI: "/a.json", Content: bytes.NewBufferString(`{"a":2}`), Metadata: &Metadata{}, VersionID: 1234}
+	results, err := service.WriteConcurrent([]*DocumentDescription{doc}, 0, nil, nil)
+	var conflict *VersionConflictError
+	if !errors.Is(err, ErrVersionConflict) || !errors.As(err, &conflict) || conflict.URI != "/a.json" {
+		t.Errorf("Write Error = %+v, Want = %+v", err, ErrVersionConflict)
+	}
+	// the version of a failed write is unknown
+	if results[0].VersionID != 0 {
+		t.Errorf("Result VersionID = %+v, Want = %+v", results[0].VersionID, 0)
+	}
+	err = service.DeleteIfVersion("/a.json", 1234, "", nil, nil)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Delete Error = %+v, Want = %+v", err, ErrVersionConflict)
+	}
+	if err := service.DeleteIfVersion("/a.json", 5678, "", nil, nil); err != nil {
+		t.Errorf("Error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// ErrBatchRolledBack is the error of documents in a failed WriteSet that were
+// not written because another document in the same request failed
+var ErrBatchRolledBack = errors.New("document not written: the batch was rolled back")
+
+// WriteSetResult describes the documents written by a multi-document write
+type WriteSetResult struct {
+	XMLName   xml.Name            `xml:"http://marklogic.com/rest-api documents" json:"-"`
+	Documents []*WriteSetDocument `xml:"http://marklogic.com/rest-api document" json:"documents"`
+}
+
+// WriteSetDocument describes a single document of a multi-document write.
+// Categories lists what was written for the document (content, metadata).
+type WriteSetDocument struct {
+	URI        string   `xml:"http://marklogic.com/rest-api uri" json:"uri"`
+	MimeType   string   `xml:"http://marklogic.com/rest-api mime-type" json:"mime-type"`
+	Categories []string `xml:"http://marklogic.com/rest-api category" json:"category"`
+	Err        error    `xml:"-" json:"-"`
+}
+
+// Failed returns the documents that were not written
+func (wr *WriteSetResult) Failed() []*WriteSetDocument {
+	failed := []*WriteSetDocument{}
+	for _, document := range wr.Documents {
+		if document.Err != nil {
+			failed = append(failed, document)
+		}
+	}
+	return failed
+}
+
+// failedWriteSetResult attributes the error of a rejected multi-document
+// write. Documents whose URI appears in the error message get the error, the
+// rest of the batch ErrBatchRolledBack; when no URI can be identified every
+// document gets the error.
+func failedWriteSetResult(documents []*DocumentDescription, mimeTypes []string, err error) *WriteSetResult {
+	message := err.Error()
+	var statusError *util.StatusError
+	if errors.As(err, &statusError) {
+		message = statusError.Body
+	}
+	result := &WriteSetResult{Documents: make([]*WriteSetDocument, 0, len(documents))}
+	identified := false
+	for i, doc := range documents {
+		if doc.defaultMetadata {
+			continue
+		}
+		writeSetDocument := &WriteSetDocument{URI: doc.URI, MimeType: mimeTypes[i], Err: ErrBatchRolledBack}
+		if doc.URI != "" && strings.Contains(message, doc.URI) {
+			writeSetDocument.Err = err
+			identified = true
+		}
+		result.Documents = append(result.Documents, writeSetDocument)
+	}
+	if !identified {
+		for _, writeSetDocument := range result.Documents {
+			writeSetDocument.Err = err
+		}
+	}
+	return result
+}
+
+// writeSetHandle reads the response of a multi-document write into a
+// WriteSetResult
+type writeSetHandle struct {
+	*bytes.Buffer
+	Format    int
+	result    WriteSetResult
+	timestamp string
+}
+
+// GetFormat returns int that represents XML or JSON
+func (wh *writeSetHandle) GetFormat() int {
+	return wh.Format
+}
+
+func (wh *writeSetHandle) resetBuffer() {
+	if wh.Buffer == nil {
+		wh.Buffer = new(bytes.Buffer)
+	}
+	wh.Reset()
+}
+
+// Deserialize returns WriteSetResult struct that represents XML or JSON
+func (wh *writeSetHandle) Deserialize(bytes []byte) {
+	wh.resetBuffer()
+	wh.Write(bytes)
+	wh.result = WriteSetResult{}
+	if wh.GetFormat() == handle.XML {
+		xml.Unmarshal(bytes, &wh.result)
+	} else {
+		json.Unmarshal(bytes, &wh.result)
+	}
+}
+
+// Deserialized returns *WriteSetResult as interface{}
+func (wh *writeSetHandle) Deserialized() interface{} {
+	return &wh.result
+}
+
+// Serialize returns []byte of XML or JSON that represents the WriteSetResult struct
+func (wh *writeSetHandle) Serialize(result interface{}) {
+	wh.result = *result.(*WriteSetResult)
+	wh.resetBuffer()
+	if wh.GetFormat() == handle.XML {
+		xml.NewEncoder(wh).Encode(&wh.result)
+	} else {
+		json.NewEncoder(wh).Encode(&wh.result)
+	}
+}
+
+// Serialized returns string of XML or JSON
+func (wh *writeSetHandle) Serialized() string {
+	wh.Serialize(&wh.result)
+	return wh.String()
+}
+
+// Get returns the parsed WriteSetResult
+func (wh *writeSetHandle) Get() *WriteSetResult {
+	return &wh.result
+}
+
+// SetTimestamp sets the timestamp
+func (wh *writeSetHandle) SetTimestamp(timestamp string) {
+	wh.timestamp = timestamp
+}
+
+// Timestamp retieves a timestamp
+func (wh *writeSetHandle) Timestamp() string {
+	return wh.timestamp
+}
+
+// AcceptResponse handles an *http.Response
+func (wh *writeSetHandle) AcceptResponse(resp *http.Response) error {
+	return handle.CommonHandleAcceptResponse(wh, resp)
+}
@@ -0,0 +1,151 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+	util "github.com/ryanjdew/go-marklogic-go/util"
+)
+
+func TestWriteSetResult(t *testing.T) {
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"documents":[` +
+			`{"uri":"/a.json","mime-type":"application/json","category":["metadata","content"]},` +
+			`{"uri":"/b.xml","mime-type":"application/xml","category":["content"]}]}`))
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{
+		{URI: "/a.json", Content: bytes.NewBufferString(`{"a":1}`), Metadata: &Metadata{Collections: []string{"c"}}},
+		{URI: "/b.xml", Content: bytes.NewBufferString(`<b/>`)},
+	}
+	raw := &handle.RawHandle{}
+	result, err := NewService(client).WriteSetWithResult(docs, &MetadataHandle{}, nil, nil, raw)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := []*WriteSetDocument{
+		{URI: "/a.json", MimeType: "application/json", Categories: []string{"metadata", "content"}},
+		{URI: "/b.xml", MimeType: "application/xml", Categories: []string{"content"}},
+	}
+	if !reflect.DeepEqual(result.Documents, want) {
+		t.Errorf("WriteSet Results = %+v, Want = %+v", result.Documents, want)
+	}
+	if len(result.Failed()) != 0 || raw.Get() == "" {
+		t.Errorf("WriteSet Failed = %+v, Raw = %+v", result.Failed(), raw.Get())
+	}
+}
+
+func TestWriteSetHandleXML(t *testing.T) {
+	wh := &writeSetHandle{Format: handle.XML}
+	wh.Deserialize([]byte(`<rapi:documents xmlns:rapi="http://marklogic.com/rest-api">` +
+		`<rapi:document><rapi:uri>/b.xml</rapi:uri><rapi:mime-type>application/xml</rapi:mime-type>` +
+		`<rapi:category>metadata</rapi:category><rapi:category>content</rapi:category></rapi:document></rapi:documents>`))
+	want := []*WriteSetDocument{{URI: "/b.xml", MimeType: "application/xml", Categories: []string{"metadata", "content"}}}
+	if !reflect.DeepEqual(wh.Get().Documents, want) {
+		t.Errorf("WriteSet Results = %+v, Want = %+v", wh.Get().Documents, want)
+	}
+}
+
+func TestWriteSetFailure(t *testing.T) {
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorResponse":{"message":"XDMP-DOCROOTTEXT: Invalid root text in /bad.xml"}}`))
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{
+		{URI: "/good.json", Content: bytes.NewBufferString(`{"a":1}`)},
+		{URI: "/bad.xml", Content: bytes.NewBufferString(`text`), Format: handle.XML},
+	}
+	result, err := NewService(client).WriteSetWithResult(docs, &MetadataHandle{}, nil, nil, nil)
+	var statusError *util.StatusError
+	if !errors.As(err, &statusError) {
+		t.Fatalf("Error = %+v, Want = %+v", err, "status error")
+	}
+	if result.Documents[0].Err != ErrBatchRolledBack || result.Documents[1].Err != err {
+		t.Errorf("WriteSet Errors = %+v, %+v", result.Documents[0].Err, result.Documents[1].Err)
+	}
+	if failed := result.Failed(); len(failed) != 2 || failed[1].MimeType != "application/xml" {
+		t.Errorf("WriteSet Failed = %+v", failed)
+	}
+}
+
+func multipartParts(r *http.Request) []string {
+	parts := []string{}
+	_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return parts
+		}
+		body, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get("Content-Disposition")+" "+strings.TrimSpace(string(body)))
+	}
+}
+
+func TestWriteSetDefaultMetadata(t *testing.T) {
+	var parts []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts = multipartParts(r)
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	defaults := &MetadataHandle{}
+	defaults.Serialize(Metadata{Collections: []string{"batch"}})
+	docs := []*DocumentDescription{
+		{URI: "/a.json", Content: bytes.NewBufferString(`{}`)},
+		DefaultMetadata(&Metadata{Quality: 2}),
+		{URI: "/b.json", Content: bytes.NewBufferString(`{}`), Metadata: &Metadata{Collections: []string{"own"}}},
+		DefaultMetadata(nil),
+		{URI: "/c.json", Content: bytes.NewBufferString(`{}`)},
+	}
+	if err := NewService(client).WriteSet(docs, defaults, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := []string{
+		`inline; category=metadata {"collections":["batch"]}`,
+		`attachment; filename="/a.json" {}`,
+		`inline; category=metadata {"quality":2}`,
+		`inline; category=metadata; filename="/b.json" {"collections":["own"]}`,
+		`attachment; filename="/b.json" {}`,
+		`inline; category=metadata {}`,
+		`attachment; filename="/c.json" {}`,
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("WriteSet Parts = %+v, Want = %+v", parts, want)
+	}
+	if uris := ToURIs(docs); len(uris) != 3 {
+		t.Errorf("URIs = %+v, Want = %+v", uris, 3)
+	}
+}
+
+func TestWriteSetRevertDefaultMetadata(t *testing.T) {
+	var contentType, body string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		part, _ := multipart.NewReader(r.Body, params["boundary"]).NextPart()
+		raw, _ := io.ReadAll(part)
+		contentType, body = part.Header.Get("Content-Type"), string(raw)
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+	docs := []*DocumentDescription{DefaultMetadata(nil), {URI: "/a.json", Content: bytes.NewBufferString(`{}`)}}
+	if err := NewService(client).WriteSet(docs, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if contentType != "application/json" || body != "{}" {
+		t.Errorf("Metadata Part = %+v %+v, Want = %+v", contentType, body, "application/json {}")
+	}
+}
@@ -0,0 +1,207 @@
+package goMarklogicGo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// extensionMimeTypes mirrors the default mimetypes configuration of MarkLogic
+// Server so content is stored with the same format the server would infer
+var extensionMimeTypes = map[string]string{
+	"json":   "application/json",
+	"xml":    "application/xml",
+	"xsd":    "application/xml",
+	"xsl":    "application/xslt+xml",
+	"xslt":   "application/xslt+xml",
+	"xhtml":  "application/xhtml+xml",
+	"svg":    "image/svg+xml",
+	"rdf":    "application/rdf+xml",
+	"atom":   "application/atom+xml",
+	"txt":    "text/plain",
+	"text":   "text/plain",
+	"log":    "text/plain",
+	"csv":    "text/csv",
+	"tsv":    "text/tab-separated-values",
+	"css":    "text/css",
+	"md":     "text/markdown",
+	"html":   "text/html",
+	"htm":    "text/html",
+	"js":     "application/javascript",
+	"mjs":    "application/javascript",
+	"sjs":    "application/vnd.marklogic-javascript",
+	"xqy":    "application/vnd.marklogic-xdmp",
+	"xq":     "application/vnd.marklogic-xdmp",
+	"ttl":    "text/turtle",
+	"nt":     "application/n-triples",
+	"nq":     "application/n-quads",
+	"sparql": "application/sparql-query",
+	"yaml":   "application/x-yaml",
+	"yml":    "application/x-yaml",
+	"pdf":    "application/pdf",
+	"png":    "image/png",
+	"jpg":    "image/jpeg",
+	"jpeg":   "image/jpeg",
+	"gif":    "image/gif",
+	"bmp":    "image/bmp",
+	"ico":    "image/x-icon",
+	"tif":    "image/tiff",
+	"tiff":   "image/tiff",
+	"webp":   "image/webp",
+	"mp3":    "audio/mpeg",
+	"wav":    "audio/wav",
+	"ogg":    "audio/ogg",
+	"mp4":    "video/mp4",
+	"m4v":    "video/mp4",
+	"mov":    "video/quicktime",
+	"avi":    "video/x-msvideo",
+	"webm":   "video/webm",
+	"zip":    "application/zip",
+	"gz":     "application/gzip",
+	"tar":    "application/x-tar",
+	"doc":    "application/msword",
+	"docx":   "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xls":    "application/vnd.ms-excel",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"ppt":    "application/vnd.ms-powerpoint",
+	"pptx":   "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"bin":    "application/octet-stream",
+}
+
+// textMimeTypes are non text/* mime types MarkLogic stores as text documents
+var textMimeTypes = map[string]bool{
+	"application/vnd.marklogic-javascript": true,
+	"application/vnd.marklogic-xdmp":       true,
+	"application/n-triples":                true,
+	"application/n-quads":                  true,
+	"application/sparql-query":             true,
+	"application/x-yaml":                   true,
+}
+
+// MimeTypeForExtension returns the mime type for a file extension (with or
+// without the leading dot) or "" if the extension is unknown
+func MimeTypeForExtension(extension string) string {
+	return extensionMimeTypes[strings.ToLower(strings.TrimPrefix(extension, "."))]
+}
+
+// MimeTypeForURI returns the mime type for the extension of a document URI or
+// "" if the URI has no known extension
+func MimeTypeForURI(uri string) string {
+	return MimeTypeForExtension(path.Ext(uri))
+}
+
+// SniffMimeType determines a mime type from the leading bytes of content.
+// JSON and XML are recognized first, then the content sniffing algorithm of
+// net/http is used. The content may be a truncated prefix of the document.
+func SniffMimeType(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return ""
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && isJSON(trimmed) {
+		return "application/json"
+	}
+	if trimmed[0] == '<' && isXML(trimmed) {
+		return "application/xml"
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if mimeType == "text/xml" {
+		return "application/xml"
+	}
+	return mimeType
+}
+
+// isJSON tells whether content is JSON or the valid beginning of JSON
+func isJSON(content []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func isXML(content []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			return true
+		}
+	}
+}
+
+// DetectMimeType determines the mime type of a document from its URI
+// extension, falling back to sniffing its content. JSON is returned when
+// neither gives an answer.
+func DetectMimeType(uri string, content []byte) string {
+	if mimeType := MimeTypeForURI(uri); mimeType != "" {
+		return mimeType
+	}
+	if mimeType := SniffMimeType(content); mimeType != "" {
+		return mimeType
+	}
+	return FormatEnumToMimeType(JSON)
+}
+
+// MimeTypeToFormatEnum converts a mime/type value to the closest format enum
+func MimeTypeToFormatEnum(mimeType string) int {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	switch {
+	case mediaType == "":
+		return UNKNOWN
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return JSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return XML
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return MIXED
+	case mediaType == "text/uri-list":
+		return TEXT_URI_LIST
+	case mediaType == "text/html":
+		return TEXTHTML
+	case mediaType == "text/csv":
+		return TEXTCSV
+	case mediaType == "text/css":
+		return TEXTCSS
+	case mediaType == "text/markdown":
+		return TEXTMARKDOWN
+	case mediaType == "application/javascript" || mediaType == "text/javascript":
+		return TEXTJAVASCRIPT
+	case strings.HasPrefix(mediaType, "text/") || textMimeTypes[mediaType]:
+		return TEXTPLAIN
+	}
+	return BINARY
+}
+
+// DocumentFormatToFormatEnum converts a MarkLogic document format (json, xml,
+// text or binary), as reported in the vnd.marklogic.document-format header, to
+// a format enum
+func DocumentFormatToFormatEnum(documentFormat string) int {
+	switch strings.ToLower(documentFormat) {
+	case "json":
+		return JSON
+	case "xml":
+		return XML
+	case "text":
+		return TEXTPLAIN
+	case "binary":
+		return BINARY
+	}
+	return UNKNOWN
+}
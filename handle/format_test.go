@@ -0,0 +1,70 @@
+package goMarklogicGo
+
+import "testing"
+
+func TestDetectMimeType(t *testing.T) {
+	cases := []struct {
+		uri     string
+		content string
+		want    string
+	}{
+		{"/doc.json", `<root/>`, "application/json"},
+		{"/doc.XML", `{}`, "application/xml"},
+		{"/video/clip.mp4", "", "video/mp4"},
+		{"/docs/report.pdf", "", "application/pdf"},
+		{"/notes.md", "", "text/markdown"},
+		{"/no-extension", `  {"a": [1, 2, "trunc`, "application/json"},
+		{"/no-extension", `<?xml version="1.0"?><root/>`, "application/xml"},
+		{"/no-extension", "<!-- note --><a:root xmlns:a='urn:a'/>", "application/xml"},
+		{"/no-extension", "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR", "image/png"},
+		{"/no-extension", "%PDF-1.7", "application/pdf"},
+		{"/no-extension", "plain words", "text/plain"},
+		{"/no-extension", "{not json", "text/plain"},
+		{"/no-extension", "", "application/json"},
+	}
+	for _, c := range cases {
+		if result := DetectMimeType(c.uri, []byte(c.content)); result != c.want {
+			t.Errorf("DetectMimeType(%q, %q) = %+v, Want = %+v", c.uri, c.content, result, c.want)
+		}
+	}
+}
+
+func TestMimeTypeToFormatEnum(t *testing.T) {
+	cases := map[string]int{
+		"application/json":                     JSON,
+		"application/ld+json":                  JSON,
+		"text/xml; charset=utf-8":              XML,
+		"image/svg+xml":                        XML,
+		"multipart/mixed; boundary=x":          MIXED,
+		"text/plain":                           TEXTPLAIN,
+		"text/turtle":                          TEXTPLAIN,
+		"application/vnd.marklogic-javascript": TEXTPLAIN,
+		"text/html":                            TEXTHTML,
+		"text/csv":                             TEXTCSV,
+		"text/css":                             TEXTCSS,
+		"text/markdown":                        TEXTMARKDOWN,
+		"application/javascript":               TEXTJAVASCRIPT,
+		"image/png":                            BINARY,
+		"application/octet-stream":             BINARY,
+		"":                                     UNKNOWN,
+	}
+	for mimeType, want := range cases {
+		if result := MimeTypeToFormatEnum(mimeType); result != want {
+			t.Errorf("MimeTypeToFormatEnum(%q) = %+v, Want = %+v", mimeType, result, want)
+		}
+	}
+	for _, format := range []int{JSON, XML, TEXTPLAIN, TEXTHTML, TEXTCSV, TEXTCSS, TEXTMARKDOWN, TEXTJAVASCRIPT, BINARY} {
+		if result := MimeTypeToFormatEnum(FormatEnumToMimeType(format)); result != format {
+			t.Errorf("Round trip of format %d = %+v", format, result)
+		}
+	}
+}
+
+func TestDocumentFormatToFormatEnum(t *testing.T) {
+	cases := map[string]int{"json": JSON, "XML": XML, "text": TEXTPLAIN, "binary": BINARY, "": UNKNOWN}
+	for documentFormat, want := range cases {
+		if result := DocumentFormatToFormatEnum(documentFormat); result != want {
+			t.Errorf("DocumentFormatToFormatEnum(%q) = %+v, Want = %+v", documentFormat, result, want)
+		}
+	}
+}
@@ -18,24 +18,38 @@ const (
 	TEXT_URI_LIST
 	TEXTHTML
 	UNKNOWN
+	BINARY
+	TEXTCSV
+	TEXTCSS
+	TEXTMARKDOWN
+	TEXTJAVASCRIPT
 )
 
 // FormatEnumToMimeType converts a format enum to a mime/type value for the REST API
 func FormatEnumToMimeType(formatEnum int) string {
 	var formatStr string
-	if formatEnum == JSON {
+	switch formatEnum {
+	case JSON:
 		formatStr = "application/json"
-	} else if formatEnum == MIXED {
+	case XML:
+		formatStr = "application/xml"
+	case MIXED:
 		formatStr = "multipart/mixed"
-	} else if formatEnum == TEXTPLAIN {
+	case TEXTPLAIN:
 		formatStr = "text/plain"
-	} else if formatEnum == TEXT_URI_LIST {
+	case TEXT_URI_LIST:
 		formatStr = "text/uri-list"
-	} else if formatEnum == XML {
-		formatStr = "application/xml"
-	} else if formatEnum == TEXTHTML {
+	case TEXTHTML:
 		formatStr = "text/html"
-	} else {
+	case TEXTCSV:
+		formatStr = "text/csv"
+	case TEXTCSS:
+		formatStr = "text/css"
+	case TEXTMARKDOWN:
+		formatStr = "text/markdown"
+	case TEXTJAVASCRIPT:
+		formatStr = "application/javascript"
+	default:
 		formatStr = "application/octet-stream"
 	}
 	return formatStr
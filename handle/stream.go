@@ -0,0 +1,75 @@
+package goMarklogicGo
+
+import (
+	"io"
+	"net/http"
+)
+
+// StreamHandle copies a response body to Writer as it is received instead
+// of buffering it in memory. Header and StatusCode hold the response
+// headers and status, and Written the number of bytes copied.
+type StreamHandle struct {
+	Writer     io.Writer
+	Format     int
+	Header     http.Header
+	StatusCode int
+	Written    int64
+	timestamp  string
+}
+
+// GetFormat returns int that represents the format of the content
+func (sh *StreamHandle) GetFormat() int {
+	return sh.Format
+}
+
+// Read returns io.EOF; the content is only available from Writer
+func (sh *StreamHandle) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+// Write writes to Writer
+func (sh *StreamHandle) Write(p []byte) (int, error) {
+	n, err := sh.Writer.Write(p)
+	sh.Written += int64(n)
+	return n, err
+}
+
+// Deserialize writes bytes to Writer
+func (sh *StreamHandle) Deserialize(bytes []byte) {
+	sh.Write(bytes)
+}
+
+// Deserialized returns the number of bytes written as interface{}
+func (sh *StreamHandle) Deserialized() interface{} {
+	return sh.Written
+}
+
+// Serialize writes []byte content to Writer
+func (sh *StreamHandle) Serialize(bytes interface{}) {
+	sh.Deserialize(bytes.([]byte))
+}
+
+// Serialized returns an empty string as streamed content is not kept
+func (sh *StreamHandle) Serialized() string {
+	return ""
+}
+
+// SetTimestamp sets the timestamp
+func (sh *StreamHandle) SetTimestamp(timestamp string) {
+	sh.timestamp = timestamp
+}
+
+// Timestamp retieves a timestamp
+func (sh *StreamHandle) Timestamp() string {
+	return sh.timestamp
+}
+
+// AcceptResponse copies the body of an *http.Response to Writer
+func (sh *StreamHandle) AcceptResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	sh.Header = resp.Header
+	sh.StatusCode = resp.StatusCode
+	sh.SetTimestamp(resp.Header.Get("ML-Effective-Timestamp"))
+	_, err := io.Copy(sh, resp.Body)
+	return err
+}
@@ -0,0 +1,25 @@
+package goMarklogicGo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStreamHandle(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	sh := &StreamHandle{Writer: buffer, Format: BINARY}
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"video/mp4"}},
+		Body:       io.NopCloser(strings.NewReader("binary content")),
+	}
+	if err := sh.AcceptResponse(resp); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if buffer.String() != "binary content" || sh.Written != 14 || sh.Header.Get("Content-Type") != "video/mp4" || sh.Serialized() != "" {
+		t.Errorf("Stream Results = %q %v, Want = %q %v", buffer.String(), sh.Written, "binary content", 14)
+	}
+}
@@ -0,0 +1,619 @@
+package goMarklogicGo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+	xmlnsNamespace = "http://www.w3.org/2000/xmlns/"
+)
+
+// XMLNodeType identifies the kind of an XMLNode
+type XMLNodeType int
+
+// XML node types
+const (
+	DocumentNode XMLNodeType = iota
+	ElementNode
+	AttributeNode
+	TextNode
+	CommentNode
+	ProcInstNode
+	DirectiveNode
+)
+
+// XMLAttr is an attribute on an element. Name.Space holds the namespace URI and
+// Prefix the prefix the attribute was written with. Namespace declarations
+// (xmlns and xmlns:prefix) are kept as attributes so they survive a round trip.
+type XMLAttr struct {
+	Name   xml.Name
+	Prefix string
+	Value  string
+}
+
+// IsNamespaceDecl tells whether the attribute is a namespace declaration
+func (a XMLAttr) IsNamespaceDecl() bool {
+	return a.Name.Space == xmlnsNamespace
+}
+
+// XMLNode is a node in a namespace aware XML DOM tree. For elements Name.Space
+// holds the namespace URI and Prefix the prefix the element was written with.
+// Data holds the content of text, comment and directive nodes, the instruction
+// of processing instructions and the value of attribute nodes.
+type XMLNode struct {
+	Type     XMLNodeType
+	Name     xml.Name
+	Prefix   string
+	Attrs    []XMLAttr
+	Data     string
+	Parent   *XMLNode
+	Children []*XMLNode
+}
+
+// NewXMLDocument returns an empty document node
+func NewXMLDocument() *XMLNode {
+	return &XMLNode{Type: DocumentNode}
+}
+
+// NewXMLElement returns a detached element in the given namespace
+func NewXMLElement(space string, local string) *XMLNode {
+	return &XMLNode{Type: ElementNode, Name: xml.Name{Space: space, Local: local}}
+}
+
+// NewXMLText returns a detached text node
+func NewXMLText(text string) *XMLNode {
+	return &XMLNode{Type: TextNode, Data: text}
+}
+
+// ParseXML parses XML into a document node
+func ParseXML(data []byte) (*XMLNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	doc := NewXMLDocument()
+	current := doc
+	scope := &nsScope{bindings: map[string]string{"xml": xmlNamespace}}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			scope = &nsScope{parent: scope, bindings: map[string]string{}}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					scope.bindings[attr.Name.Local] = attr.Value
+				} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					scope.bindings[""] = attr.Value
+				}
+			}
+			space, ok := scope.lookup(t.Name.Space)
+			if !ok {
+				return nil, fmt.Errorf("undeclared namespace prefix %q", t.Name.Space)
+			}
+			element := NewXMLElement(space, t.Name.Local)
+			element.Prefix = t.Name.Space
+			for _, attr := range t.Attr {
+				xmlAttr := XMLAttr{Name: xml.Name{Local: attr.Name.Local}, Prefix: attr.Name.Space, Value: attr.Value}
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					xmlAttr.Name.Space = xmlnsNamespace
+				} else if attr.Name.Space != "" {
+					attrSpace, ok := scope.lookup(attr.Name.Space)
+					if !ok {
+						return nil, fmt.Errorf("undeclared namespace prefix %q", attr.Name.Space)
+					}
+					xmlAttr.Name.Space = attrSpace
+				}
+				element.Attrs = append(element.Attrs, xmlAttr)
+			}
+			current.AppendChild(element)
+			current = element
+		case xml.EndElement:
+			if current.Type != ElementNode || current.Prefix != t.Name.Space || current.Name.Local != t.Name.Local {
+				return nil, fmt.Errorf("unexpected end element </%s>", qualifiedName(t.Name.Space, t.Name.Local))
+			}
+			current = current.Parent
+			scope = scope.parent
+		case xml.CharData:
+			current.AppendChild(NewXMLText(string(t)))
+		case xml.Comment:
+			current.AppendChild(&XMLNode{Type: CommentNode, Data: string(t)})
+		case xml.ProcInst:
+			current.AppendChild(&XMLNode{Type: ProcInstNode, Name: xml.Name{Local: t.Target}, Data: string(t.Inst)})
+		case xml.Directive:
+			current.AppendChild(&XMLNode{Type: DirectiveNode, Data: string(t)})
+		}
+	}
+	if current != doc {
+		return nil, errors.New("unexpected end of XML: unclosed element <" + qualifiedName(current.Prefix, current.Name.Local) + ">")
+	}
+	return doc, nil
+}
+
+// Root returns the top of the tree the node belongs to
+func (n *XMLNode) Root() *XMLNode {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// DocumentElement returns the first element child of a document node
+func (n *XMLNode) DocumentElement() *XMLNode {
+	for _, child := range n.Root().Children {
+		if child.Type == ElementNode {
+			return child
+		}
+	}
+	return nil
+}
+
+// Elements returns the element children of the node
+func (n *XMLNode) Elements() []*XMLNode {
+	elements := []*XMLNode{}
+	for _, child := range n.Children {
+		if child.Type == ElementNode {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// Text returns the string value of the node. For elements and documents this
+// is the concatenation of all descendant text.
+func (n *XMLNode) Text() string {
+	switch n.Type {
+	case DocumentNode, ElementNode:
+		var builder strings.Builder
+		n.appendText(&builder)
+		return builder.String()
+	default:
+		return n.Data
+	}
+}
+
+func (n *XMLNode) appendText(builder *strings.Builder) {
+	for _, child := range n.Children {
+		if child.Type == TextNode {
+			builder.WriteString(child.Data)
+		} else if child.Type == ElementNode {
+			child.appendText(builder)
+		}
+	}
+}
+
+// SetText replaces the children of the node with a single text node
+func (n *XMLNode) SetText(text string) {
+	if n.Type != DocumentNode && n.Type != ElementNode {
+		n.Data = text
+		return
+	}
+	for _, child := range n.Children {
+		child.Parent = nil
+	}
+	n.Children = nil
+	n.AppendChild(NewXMLText(text))
+}
+
+// Attr returns the value of the attribute with the given namespace and local name
+func (n *XMLNode) Attr(space string, local string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr adds or replaces the attribute with the given namespace and local name
+func (n *XMLNode) SetAttr(space string, local string, value string) {
+	for i, attr := range n.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, XMLAttr{Name: xml.Name{Space: space, Local: local}, Value: value})
+}
+
+// RemoveAttr removes the attribute with the given namespace and local name
+func (n *XMLNode) RemoveAttr(space string, local string) bool {
+	for i, attr := range n.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			n.Attrs = append(n.Attrs[:i], n.Attrs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AppendChild adds child as the last child of the node, detaching it from any
+// previous parent
+func (n *XMLNode) AppendChild(child *XMLNode) {
+	child.Remove()
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// InsertBefore adds child before ref. If ref is not a child of the node the
+// child is appended.
+func (n *XMLNode) InsertBefore(child *XMLNode, ref *XMLNode) {
+	child.Remove()
+	for i, existing := range n.Children {
+		if existing == ref {
+			child.Parent = n
+			n.Children = append(n.Children[:i], append([]*XMLNode{child}, n.Children[i:]...)...)
+			return
+		}
+	}
+	n.AppendChild(child)
+}
+
+// RemoveChild detaches child from the node
+func (n *XMLNode) RemoveChild(child *XMLNode) bool {
+	for i, existing := range n.Children {
+		if existing == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			child.Parent = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Remove detaches the node from its parent
+func (n *XMLNode) Remove() {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// Find evaluates an XPath expression with the node as context. The namespaces
+// map binds the prefixes used in the expression.
+func (n *XMLNode) Find(expr string, namespaces map[string]string) ([]*XMLNode, error) {
+	xpath, err := CompileXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return xpath.Evaluate(n, namespaces)
+}
+
+// FindOne returns the first node selected by an XPath expression or nil
+func (n *XMLNode) FindOne(expr string, namespaces map[string]string) (*XMLNode, error) {
+	nodes, err := n.Find(expr, namespaces)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// String serializes the node as XML
+func (n *XMLNode) String() string {
+	buffer := &bytes.Buffer{}
+	n.WriteTo(buffer)
+	return buffer.String()
+}
+
+// WriteTo serializes the node as XML, adding namespace declarations for any
+// namespace that is used but not declared
+func (n *XMLNode) WriteTo(w io.Writer) (int64, error) {
+	serializer := &xmlSerializer{}
+	scope := &nsScope{bindings: map[string]string{"xml": xmlNamespace}}
+	if n.Type == ElementNode {
+		scope = n.inheritedScope()
+	}
+	serializer.node(n, scope)
+	written, err := w.Write(serializer.Bytes())
+	return int64(written), err
+}
+
+// inheritedScope builds the namespace bindings declared by the node's ancestors
+func (n *XMLNode) inheritedScope() *nsScope {
+	ancestors := []*XMLNode{}
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		ancestors = append(ancestors, parent)
+	}
+	scope := &nsScope{bindings: map[string]string{"xml": xmlNamespace}}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		scope = &nsScope{parent: scope, bindings: ancestors[i].namespaceDecls()}
+	}
+	return scope
+}
+
+func (n *XMLNode) namespaceDecls() map[string]string {
+	bindings := map[string]string{}
+	for _, attr := range n.Attrs {
+		if attr.IsNamespaceDecl() {
+			if attr.Prefix == "" {
+				bindings[""] = attr.Value
+			} else {
+				bindings[attr.Name.Local] = attr.Value
+			}
+		}
+	}
+	return bindings
+}
+
+type nsScope struct {
+	parent   *nsScope
+	bindings map[string]string
+}
+
+func (s *nsScope) lookup(prefix string) (string, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if space, ok := scope.bindings[prefix]; ok {
+			return space, true
+		}
+	}
+	if prefix == "" {
+		return "", true
+	}
+	return "", false
+}
+
+// prefixFor finds a non-empty prefix currently bound to space
+func (s *nsScope) prefixFor(space string) (string, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		for prefix, bound := range scope.bindings {
+			if prefix != "" && bound == space {
+				if current, _ := s.lookup(prefix); current == space {
+					return prefix, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+type xmlSerializer struct {
+	bytes.Buffer
+	generated int
+}
+
+func (s *xmlSerializer) node(n *XMLNode, scope *nsScope) {
+	switch n.Type {
+	case DocumentNode:
+		for _, child := range n.Children {
+			s.node(child, scope)
+		}
+	case ElementNode:
+		s.element(n, scope)
+	case AttributeNode, TextNode:
+		s.WriteString(textEscaper.Replace(n.Data))
+	case CommentNode:
+		s.WriteString("<!--" + n.Data + "-->")
+	case ProcInstNode:
+		s.WriteString("<?" + n.Name.Local)
+		if n.Data != "" {
+			s.WriteString(" " + n.Data)
+		}
+		s.WriteString("?>")
+	case DirectiveNode:
+		s.WriteString("<!" + n.Data + ">")
+	}
+}
+
+func (s *xmlSerializer) element(n *XMLNode, parentScope *nsScope) {
+	scope := &nsScope{parent: parentScope, bindings: n.namespaceDecls()}
+	declared := []XMLAttr{}
+	declare := func(prefix string, space string) {
+		scope.bindings[prefix] = space
+		if prefix == "" {
+			declared = append(declared, XMLAttr{Name: xml.Name{Space: xmlnsNamespace, Local: "xmlns"}, Value: space})
+		} else {
+			declared = append(declared, XMLAttr{Name: xml.Name{Space: xmlnsNamespace, Local: prefix}, Prefix: "xmlns", Value: space})
+		}
+	}
+
+	prefix := n.Prefix
+	localDecls := n.namespaceDecls()
+	if bound, ok := scope.lookup(prefix); !ok || bound != n.Name.Space {
+		_, prefixDeclared := localDecls[prefix]
+		_, defaultDeclared := localDecls[""]
+		if defaultSpace, _ := scope.lookup(""); defaultSpace == n.Name.Space {
+			prefix = ""
+		} else if existing, ok := scope.prefixFor(n.Name.Space); ok && n.Name.Space != "" {
+			prefix = existing
+		} else if prefix != "" && !prefixDeclared && n.Name.Space != "" {
+			declare(prefix, n.Name.Space)
+		} else if !defaultDeclared {
+			prefix = ""
+			declare("", n.Name.Space)
+		} else {
+			prefix = s.generatePrefix(scope)
+			declare(prefix, n.Name.Space)
+		}
+	}
+
+	attrs := make([]string, 0, len(n.Attrs))
+	for _, attr := range n.Attrs {
+		if attr.IsNamespaceDecl() {
+			if attr.Prefix == "" {
+				attrs = append(attrs, "xmlns")
+			} else {
+				attrs = append(attrs, "xmlns:"+attr.Name.Local)
+			}
+			continue
+		}
+		attrPrefix := ""
+		if attr.Name.Space == xmlNamespace {
+			attrPrefix = "xml"
+		} else if attr.Name.Space != "" {
+			if bound, ok := scope.lookup(attr.Prefix); attr.Prefix != "" && ok && bound == attr.Name.Space {
+				attrPrefix = attr.Prefix
+			} else if existing, ok := scope.prefixFor(attr.Name.Space); ok {
+				attrPrefix = existing
+			} else {
+				attrPrefix = attr.Prefix
+				if _, taken := scope.bindings[attrPrefix]; attrPrefix == "" || taken {
+					attrPrefix = s.generatePrefix(scope)
+				}
+				declare(attrPrefix, attr.Name.Space)
+			}
+		}
+		attrs = append(attrs, qualifiedName(attrPrefix, attr.Name.Local))
+	}
+	s.WriteString("<" + qualifiedName(prefix, n.Name.Local))
+	for _, decl := range declared {
+		s.writeAttr(qualifiedName(decl.Prefix, decl.Name.Local), decl.Value)
+	}
+	for i, attr := range n.Attrs {
+		s.writeAttr(attrs[i], attr.Value)
+	}
+	if len(n.Children) == 0 {
+		s.WriteString("/>")
+		return
+	}
+	s.WriteString(">")
+	for _, child := range n.Children {
+		s.node(child, scope)
+	}
+	s.WriteString("</" + qualifiedName(prefix, n.Name.Local) + ">")
+}
+
+func (s *xmlSerializer) writeAttr(name string, value string) {
+	s.WriteString(" " + name + "=\"" + attrEscaper.Replace(value) + "\"")
+}
+
+func (s *xmlSerializer) generatePrefix(scope *nsScope) string {
+	for {
+		prefix := "ns" + strconv.Itoa(s.generated)
+		s.generated++
+		if _, ok := scope.lookup(prefix); !ok {
+			return prefix
+		}
+	}
+}
+
+func qualifiedName(prefix string, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// XMLHandle is a handle that parses XML into a navigable DOM tree
+type XMLHandle struct {
+	*bytes.Buffer
+	// Namespaces binds prefixes used by Find and FindOne. The empty prefix sets
+	// the namespace of unprefixed element names.
+	Namespaces map[string]string
+	document   *XMLNode
+	parseError error
+	timestamp  string
+}
+
+// GetFormat returns int that represents XML
+func (xh *XMLHandle) GetFormat() int {
+	return XML
+}
+
+func (xh *XMLHandle) resetBuffer() {
+	if xh.Buffer == nil {
+		xh.Buffer = new(bytes.Buffer)
+	}
+	xh.Reset()
+}
+
+// Deserialize parses the bytes into an XML document
+func (xh *XMLHandle) Deserialize(bytes []byte) {
+	xh.resetBuffer()
+	xh.Write(bytes)
+	xh.document, xh.parseError = ParseXML(bytes)
+}
+
+// Deserialized returns the *XMLNode document as interface{}
+func (xh *XMLHandle) Deserialized() interface{} {
+	return xh.document
+}
+
+// AcceptResponse handles an *http.Response
+func (xh *XMLHandle) AcceptResponse(resp *http.Response) error {
+	err := CommonHandleAcceptResponse(xh, resp)
+	if err == nil {
+		err = xh.parseError
+	}
+	return err
+}
+
+// Serialize takes an *XMLNode and serializes it as XML. Anything else,
+// including nil, is stored as an empty document.
+func (xh *XMLHandle) Serialize(document interface{}) {
+	node, ok := document.(*XMLNode)
+	if !ok || node == nil {
+		node = NewXMLDocument()
+	}
+	xh.document = node
+	xh.parseError = nil
+	xh.resetBuffer()
+	xh.document.WriteTo(xh.Buffer)
+}
+
+// Read bytes
+func (xh *XMLHandle) Read(bytes []byte) (n int, err error) {
+	if xh.Buffer == nil {
+		xh.Serialize(xh.document)
+	}
+	return xh.Buffer.Read(bytes)
+}
+
+// Get returns the *XMLNode document
+func (xh *XMLHandle) Get() *XMLNode {
+	return xh.document
+}
+
+// ParseError returns the error from the last Deserialize, if any
+func (xh *XMLHandle) ParseError() error {
+	return xh.parseError
+}
+
+// Serialized returns string of XML reflecting any changes made to the
+// document, or the bytes given to Deserialize when they could not be parsed
+func (xh *XMLHandle) Serialized() string {
+	if xh.parseError != nil {
+		return xh.String()
+	}
+	xh.Serialize(xh.document)
+	return xh.String()
+}
+
+// Find evaluates an XPath expression against the document
+func (xh *XMLHandle) Find(expr string) ([]*XMLNode, error) {
+	if xh.document == nil {
+		return nil, errors.New("no XML document")
+	}
+	return xh.document.Find(expr, xh.Namespaces)
+}
+
+// FindOne returns the first node selected by an XPath expression or nil
+func (xh *XMLHandle) FindOne(expr string) (*XMLNode, error) {
+	if xh.document == nil {
+		return nil, errors.New("no XML document")
+	}
+	return xh.document.FindOne(expr, xh.Namespaces)
+}
+
+// SetTimestamp sets the timestamp
+func (xh *XMLHandle) SetTimestamp(timestamp string) {
+	xh.timestamp = timestamp
+}
+
+// Timestamp retieves a timestamp
+func (xh *XMLHandle) Timestamp() string {
+	return xh.timestamp
+}
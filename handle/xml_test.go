@@ -0,0 +1,137 @@
+package goMarklogicGo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var exampleXML = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- catalog -->
+<c:catalog xmlns:c="http://example.com/catalog" xmlns="http://example.com/book" xml:lang="en">
+	<book id="1" c:status="available"><title>Moby Dick</title><year>1851</year></book>
+	<book id="2"><title>Walden</title><year>1854</year></book>
+</c:catalog>`
+
+func TestXMLRoundTrip(t *testing.T) {
+	doc, err := ParseXML([]byte(exampleXML))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + strings.SplitN(exampleXML, "?>", 2)[1]
+	if result := doc.String(); result != want {
+		t.Errorf("XML Results = %+v, Want = %+v", result, want)
+	}
+	root := doc.DocumentElement()
+	if root.Name.Space != "http://example.com/catalog" || root.Name.Local != "catalog" || root.Prefix != "c" {
+		t.Errorf("Root Name = %+v, Prefix = %+v", root.Name, root.Prefix)
+	}
+	book := root.Elements()[0]
+	if book.Name.Space != "http://example.com/book" {
+		t.Errorf("Default Namespace = %+v, Want = %+v", book.Name.Space, "http://example.com/book")
+	}
+	if status, _ := book.Attr("http://example.com/catalog", "status"); status != "available" {
+		t.Errorf("Namespaced Attribute = %+v, Want = %+v", status, "available")
+	}
+	if lang, _ := root.Attr(xmlNamespace, "lang"); lang != "en" {
+		t.Errorf("xml:lang = %+v, Want = %+v", lang, "en")
+	}
+}
+
+func TestXMLParseErrors(t *testing.T) {
+	for _, input := range []string{`<a><b></a>`, `<p:a/>`, `<a>`} {
+		if _, err := ParseXML([]byte(input)); err == nil {
+			t.Errorf("Expected error parsing %s", input)
+		}
+	}
+}
+
+func TestXMLModify(t *testing.T) {
+	doc, _ := ParseXML([]byte(exampleXML))
+	root := doc.DocumentElement()
+	walden := root.Elements()[1]
+	walden.Remove()
+	book := NewXMLElement("http://example.com/book", "book")
+	book.SetAttr("", "id", "3")
+	title := NewXMLElement("http://example.com/book", "title")
+	title.SetText("Emma & Persuasion")
+	book.AppendChild(title)
+	note := NewXMLElement("http://example.com/notes", "note")
+	note.SetAttr("http://example.com/notes", "by", "editor")
+	book.AppendChild(note)
+	root.AppendChild(book)
+	root.Elements()[0].SetAttr("", "id", "one")
+	root.Elements()[0].RemoveAttr("http://example.com/catalog", "status")
+
+	want := `<c:catalog xmlns:c="http://example.com/catalog" xmlns="http://example.com/book" xml:lang="en">
+	<book id="one"><title>Moby Dick</title><year>1851</year></book>
+	` + `
+<book id="3"><title>Emma &amp; Persuasion</title><note xmlns="http://example.com/notes" xmlns:ns0="http://example.com/notes" ns0:by="editor"/></book></c:catalog>`
+	if result := root.String(); result != want {
+		t.Errorf("XML Results = %+v, Want = %+v", result, want)
+	}
+	reparsed, err := ParseXML([]byte(root.String()))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if by, _ := reparsed.DocumentElement().Elements()[1].Elements()[1].Attr("http://example.com/notes", "by"); by != "editor" {
+		t.Errorf("Reparsed Attribute = %+v, Want = %+v", by, "editor")
+	}
+}
+
+func TestXMLSerializeDetachedElement(t *testing.T) {
+	doc, _ := ParseXML([]byte(exampleXML))
+	title, _ := doc.FindOne("//b:title", map[string]string{"b": "http://example.com/book"})
+	want := `<title xmlns="http://example.com/book">Moby Dick</title>`
+	detached := &XMLNode{Type: ElementNode, Name: title.Name, Children: title.Children}
+	if result := detached.String(); result != want {
+		t.Errorf("XML Results = %+v, Want = %+v", result, want)
+	}
+	if result := title.String(); result != `<title>Moby Dick</title>` {
+		t.Errorf("XML Results = %+v, Want = %+v", result, `<title>Moby Dick</title>`)
+	}
+}
+
+func TestXMLHandle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(exampleXML))
+	}))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	xh := &XMLHandle{Namespaces: map[string]string{"": "http://example.com/book"}}
+	if err := xh.AcceptResponse(resp); err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	title, err := xh.FindOne("//book[@id='2']/title")
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if title == nil || title.Text() != "Walden" {
+		t.Fatalf("Title = %+v, Want = %+v", title, "Walden")
+	}
+	title.SetText("Walden; or, Life in the Woods")
+	if !strings.Contains(xh.Serialized(), "<title>Walden; or, Life in the Woods</title>") {
+		t.Errorf("Serialized XML missing modification: %s", xh.Serialized())
+	}
+	if xh.GetFormat() != XML {
+		t.Errorf("Format = %+v, Want = %+v", xh.GetFormat(), XML)
+	}
+	bad := &XMLHandle{}
+	bad.Deserialize([]byte("<a>"))
+	if bad.ParseError() == nil {
+		t.Errorf("Expected parse error")
+	}
+	// the unparseable response can still be inspected
+	if result := bad.Serialized(); result != "<a>" {
+		t.Errorf("Serialized Results = %+v, Want = %+v", result, "<a>")
+	}
+	empty := &XMLHandle{}
+	empty.Serialize(nil)
+	if empty.Get() == nil || empty.Get().Type != DocumentNode || empty.Serialized() != "" {
+		t.Errorf("Serialize(nil) Results = %+v %+v", empty.Get(), empty.Serialized())
+	}
+}
@@ -0,0 +1,908 @@
+package goMarklogicGo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XPath is a compiled expression in the XPath 1.0 subset supported by XMLNode.
+//
+// Supported are absolute and relative location paths, the abbreviations
+// (//, ., .., @, *), the child, descendant, descendant-or-self, self, parent,
+// ancestor, ancestor-or-self, attribute, following-sibling and
+// preceding-sibling axes, the node(), text() and comment() node tests,
+// unions (|) and predicates with positions, comparisons (=, !=, <, <=, >, >=),
+// and, or, and the functions last, position, count, not, true, false, string,
+// number, name, local-name, namespace-uri, normalize-space, contains and
+// starts-with.
+type XPath struct {
+	expr string
+	root xpathExpr
+}
+
+// CompileXPath parses an XPath expression
+func CompileXPath(expr string) (*XPath, error) {
+	tokens, err := tokenizeXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &xpathParser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != xpathEOF {
+		return nil, fmt.Errorf("xpath %q: unexpected %q", expr, p.peek().value)
+	}
+	return &XPath{expr: expr, root: root}, nil
+}
+
+// String returns the source of the expression
+func (x *XPath) String() string {
+	return x.expr
+}
+
+// Evaluate runs the expression with node as the context node. The namespaces
+// map binds prefixes used in name tests; the empty prefix, when present, is
+// used for unprefixed element names. The expression must select nodes.
+func (x *XPath) Evaluate(node *XMLNode, namespaces map[string]string) ([]*XMLNode, error) {
+	ctx := &xpathContext{namespaces: namespaces, attributes: map[*XMLNode][]*XMLNode{}}
+	value, err := x.root.eval(ctx, node, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := value.([]*XMLNode)
+	if !ok {
+		return nil, fmt.Errorf("xpath %q does not select nodes", x.expr)
+	}
+	return nodes, nil
+}
+
+type xpathContext struct {
+	namespaces map[string]string
+	attributes map[*XMLNode][]*XMLNode
+	order      map[*XMLNode]int
+}
+
+// attributesOf returns attribute nodes for an element, creating them once per
+// evaluation so they compare equal across steps
+func (ctx *xpathContext) attributesOf(n *XMLNode) []*XMLNode {
+	if n.Type != ElementNode {
+		return nil
+	}
+	if attrs, ok := ctx.attributes[n]; ok {
+		return attrs
+	}
+	attrs := []*XMLNode{}
+	for _, attr := range n.Attrs {
+		if !attr.IsNamespaceDecl() {
+			attrs = append(attrs, &XMLNode{Type: AttributeNode, Name: attr.Name, Prefix: attr.Prefix, Data: attr.Value, Parent: n})
+		}
+	}
+	ctx.attributes[n] = attrs
+	return attrs
+}
+
+func (ctx *xpathContext) sortDocumentOrder(nodes []*XMLNode) []*XMLNode {
+	if len(nodes) < 2 {
+		return nodes
+	}
+	if ctx.order == nil {
+		ctx.order = map[*XMLNode]int{}
+	}
+	unique := make([]*XMLNode, 0, len(nodes))
+	seen := map[*XMLNode]bool{}
+	for _, n := range nodes {
+		if !seen[n] {
+			seen[n] = true
+			unique = append(unique, n)
+			if _, ok := ctx.order[n]; !ok {
+				ctx.number(n.Root())
+			}
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return ctx.order[unique[i]] < ctx.order[unique[j]]
+	})
+	return unique
+}
+
+func (ctx *xpathContext) number(root *XMLNode) {
+	counter := len(ctx.order)
+	var walk func(n *XMLNode)
+	walk = func(n *XMLNode) {
+		ctx.order[n] = counter
+		counter++
+		for _, attr := range ctx.attributesOf(n) {
+			ctx.order[attr] = counter
+			counter++
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+type xpathExpr interface {
+	eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error)
+}
+
+const (
+	axisChild = iota
+	axisDescendant
+	axisDescendantOrSelf
+	axisSelf
+	axisParent
+	axisAncestor
+	axisAncestorOrSelf
+	axisAttribute
+	axisFollowingSibling
+	axisPrecedingSibling
+)
+
+var xpathAxes = map[string]int{
+	"child":              axisChild,
+	"descendant":         axisDescendant,
+	"descendant-or-self": axisDescendantOrSelf,
+	"self":               axisSelf,
+	"parent":             axisParent,
+	"ancestor":           axisAncestor,
+	"ancestor-or-self":   axisAncestorOrSelf,
+	"attribute":          axisAttribute,
+	"following-sibling":  axisFollowingSibling,
+	"preceding-sibling":  axisPrecedingSibling,
+}
+
+const (
+	testName = iota
+	testNode
+	testText
+	testComment
+)
+
+type xpathStep struct {
+	axis       int
+	test       int
+	prefix     string
+	local      string
+	predicates []xpathExpr
+}
+
+type xpathPath struct {
+	absolute bool
+	filter   xpathExpr
+	steps    []*xpathStep
+}
+
+func (p *xpathPath) eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error) {
+	var current []*XMLNode
+	if p.filter != nil {
+		value, err := p.filter.eval(ctx, node, position, size)
+		if err != nil {
+			return nil, err
+		}
+		nodes, ok := value.([]*XMLNode)
+		if !ok {
+			if len(p.steps) > 0 {
+				return nil, fmt.Errorf("xpath: path step applied to a non node-set")
+			}
+			return value, nil
+		}
+		current = nodes
+	} else if p.absolute {
+		current = []*XMLNode{node.Root()}
+	} else {
+		current = []*XMLNode{node}
+	}
+	for _, step := range p.steps {
+		next := []*XMLNode{}
+		for _, contextNode := range current {
+			selected, err := step.apply(ctx, contextNode)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, selected...)
+		}
+		if len(current) > 1 {
+			next = ctx.sortDocumentOrder(next)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func (s *xpathStep) apply(ctx *xpathContext, n *XMLNode) ([]*XMLNode, error) {
+	candidates := []*XMLNode{}
+	switch s.axis {
+	case axisChild:
+		candidates = append(candidates, n.Children...)
+	case axisDescendant, axisDescendantOrSelf:
+		if s.axis == axisDescendantOrSelf {
+			candidates = append(candidates, n)
+		}
+		var walk func(parent *XMLNode)
+		walk = func(parent *XMLNode) {
+			for _, child := range parent.Children {
+				candidates = append(candidates, child)
+				walk(child)
+			}
+		}
+		walk(n)
+	case axisSelf:
+		candidates = append(candidates, n)
+	case axisParent:
+		if n.Parent != nil {
+			candidates = append(candidates, n.Parent)
+		}
+	case axisAncestor, axisAncestorOrSelf:
+		if s.axis == axisAncestorOrSelf {
+			candidates = append(candidates, n)
+		}
+		for parent := n.Parent; parent != nil; parent = parent.Parent {
+			candidates = append(candidates, parent)
+		}
+	case axisAttribute:
+		candidates = append(candidates, ctx.attributesOf(n)...)
+	case axisFollowingSibling, axisPrecedingSibling:
+		if n.Parent != nil && n.Type != AttributeNode {
+			siblings := n.Parent.Children
+			index := 0
+			for i, sibling := range siblings {
+				if sibling == n {
+					index = i
+				}
+			}
+			if s.axis == axisFollowingSibling {
+				candidates = append(candidates, siblings[index+1:]...)
+			} else {
+				for i := index - 1; i >= 0; i-- {
+					candidates = append(candidates, siblings[i])
+				}
+			}
+		}
+	}
+	matched := []*XMLNode{}
+	for _, candidate := range candidates {
+		ok, err := s.matches(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, candidate)
+		}
+	}
+	for _, predicate := range s.predicates {
+		filtered := []*XMLNode{}
+		for i, candidate := range matched {
+			value, err := predicate.eval(ctx, candidate, i+1, len(matched))
+			if err != nil {
+				return nil, err
+			}
+			if number, ok := value.(float64); ok {
+				if number == float64(i+1) {
+					filtered = append(filtered, candidate)
+				}
+			} else if toBoolean(value) {
+				filtered = append(filtered, candidate)
+			}
+		}
+		matched = filtered
+	}
+	if s.axis == axisAncestor || s.axis == axisAncestorOrSelf || s.axis == axisPrecedingSibling {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+	return matched, nil
+}
+
+func (s *xpathStep) matches(ctx *xpathContext, n *XMLNode) (bool, error) {
+	switch s.test {
+	case testNode:
+		return true, nil
+	case testText:
+		return n.Type == TextNode, nil
+	case testComment:
+		return n.Type == CommentNode, nil
+	}
+	principal := ElementNode
+	if s.axis == axisAttribute {
+		principal = AttributeNode
+	}
+	if n.Type != principal {
+		return false, nil
+	}
+	space := ""
+	if s.prefix != "" {
+		if s.prefix == "xml" {
+			space = xmlNamespace
+		} else {
+			bound, ok := ctx.namespaces[s.prefix]
+			if !ok {
+				return false, fmt.Errorf("xpath: undeclared namespace prefix %q", s.prefix)
+			}
+			space = bound
+		}
+	} else if principal == ElementNode {
+		space = ctx.namespaces[""]
+	}
+	if s.local == "*" {
+		return s.prefix == "" || n.Name.Space == space, nil
+	}
+	return n.Name.Local == s.local && n.Name.Space == space, nil
+}
+
+type xpathUnion struct {
+	left  xpathExpr
+	right xpathExpr
+}
+
+func (u *xpathUnion) eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error) {
+	left, err := u.left.eval(ctx, node, position, size)
+	if err != nil {
+		return nil, err
+	}
+	right, err := u.right.eval(ctx, node, position, size)
+	if err != nil {
+		return nil, err
+	}
+	leftNodes, leftOk := left.([]*XMLNode)
+	rightNodes, rightOk := right.([]*XMLNode)
+	if !leftOk || !rightOk {
+		return nil, fmt.Errorf("xpath: union of non node-sets")
+	}
+	return ctx.sortDocumentOrder(append(append([]*XMLNode{}, leftNodes...), rightNodes...)), nil
+}
+
+type xpathBinary struct {
+	op    string
+	left  xpathExpr
+	right xpathExpr
+}
+
+func (b *xpathBinary) eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error) {
+	left, err := b.left.eval(ctx, node, position, size)
+	if err != nil {
+		return nil, err
+	}
+	if b.op == "and" && !toBoolean(left) {
+		return false, nil
+	}
+	if b.op == "or" && toBoolean(left) {
+		return true, nil
+	}
+	right, err := b.right.eval(ctx, node, position, size)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "and", "or":
+		return toBoolean(right), nil
+	default:
+		return compareValues(b.op, left, right), nil
+	}
+}
+
+type xpathLiteral struct {
+	value interface{}
+}
+
+func (l *xpathLiteral) eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error) {
+	return l.value, nil
+}
+
+type xpathFunction struct {
+	name string
+	args []xpathExpr
+}
+
+var xpathFunctionArity = map[string][2]int{
+	"last":            {0, 0},
+	"position":        {0, 0},
+	"count":           {1, 1},
+	"not":             {1, 1},
+	"true":            {0, 0},
+	"false":           {0, 0},
+	"string":          {0, 1},
+	"number":          {0, 1},
+	"name":            {0, 1},
+	"local-name":      {0, 1},
+	"namespace-uri":   {0, 1},
+	"normalize-space": {0, 1},
+	"contains":        {2, 2},
+	"starts-with":     {2, 2},
+}
+
+func (f *xpathFunction) eval(ctx *xpathContext, node *XMLNode, position int, size int) (interface{}, error) {
+	args := make([]interface{}, len(f.args))
+	for i, arg := range f.args {
+		value, err := arg.eval(ctx, node, position, size)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	contextNode := func() *XMLNode {
+		if len(args) == 0 {
+			return node
+		}
+		if nodes, ok := args[0].([]*XMLNode); ok && len(nodes) > 0 {
+			return nodes[0]
+		}
+		return nil
+	}
+	contextString := func() string {
+		if len(args) == 0 {
+			return node.Text()
+		}
+		return toString(args[0])
+	}
+	switch f.name {
+	case "last":
+		return float64(size), nil
+	case "position":
+		return float64(position), nil
+	case "count":
+		nodes, ok := args[0].([]*XMLNode)
+		if !ok {
+			return nil, fmt.Errorf("xpath: count() requires a node-set")
+		}
+		return float64(len(nodes)), nil
+	case "not":
+		return !toBoolean(args[0]), nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "string":
+		return contextString(), nil
+	case "number":
+		return toNumber(contextString()), nil
+	case "name", "local-name", "namespace-uri":
+		n := contextNode()
+		if n == nil {
+			return "", nil
+		}
+		if f.name == "local-name" {
+			return n.Name.Local, nil
+		} else if f.name == "namespace-uri" {
+			return n.Name.Space, nil
+		}
+		return qualifiedName(n.Prefix, n.Name.Local), nil
+	case "normalize-space":
+		return strings.Join(strings.Fields(contextString()), " "), nil
+	case "contains":
+		return strings.Contains(toString(args[0]), toString(args[1])), nil
+	case "starts-with":
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	}
+	return nil, fmt.Errorf("xpath: unknown function %s()", f.name)
+}
+
+func toBoolean(value interface{}) bool {
+	switch v := value.(type) {
+	case []*XMLNode:
+		return len(v) > 0
+	case string:
+		return v != ""
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case bool:
+		return v
+	}
+	return false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []*XMLNode:
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0].Text()
+	case string:
+		return v
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+func toNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(toString(value)), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return number
+}
+
+// compareValues follows the XPath 1.0 rules: node-sets compare true when any
+// member satisfies the comparison
+func compareValues(op string, left interface{}, right interface{}) bool {
+	if nodes, ok := left.([]*XMLNode); ok {
+		for _, n := range nodes {
+			if compareValues(op, n.Text(), right) {
+				return true
+			}
+		}
+		return false
+	}
+	if nodes, ok := right.([]*XMLNode); ok {
+		for _, n := range nodes {
+			if compareValues(op, left, n.Text()) {
+				return true
+			}
+		}
+		return false
+	}
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, leftBool := left.(bool)
+		_, rightBool := right.(bool)
+		_, leftNumber := left.(float64)
+		_, rightNumber := right.(float64)
+		if leftBool || rightBool {
+			equal = toBoolean(left) == toBoolean(right)
+		} else if leftNumber || rightNumber {
+			equal = toNumber(left) == toNumber(right)
+		} else {
+			equal = toString(left) == toString(right)
+		}
+		return equal == (op == "=")
+	}
+	l, r := toNumber(left), toNumber(right)
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+const (
+	xpathEOF = iota
+	xpathName
+	xpathString
+	xpathNumber
+	xpathOperator
+)
+
+type xpathToken struct {
+	kind  int
+	value string
+}
+
+func tokenizeXPath(expr string) ([]xpathToken, error) {
+	tokens := []xpathToken{}
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("xpath %q: unterminated string literal", expr)
+			}
+			tokens = append(tokens, xpathToken{xpathString, expr[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < len(expr) && expr[i+1] >= '0' && expr[i+1] <= '9'):
+			start := i
+			for i < len(expr) && (expr[i] >= '0' && expr[i] <= '9' || expr[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, xpathToken{xpathNumber, expr[start:i]})
+		case isNameStart(c):
+			start := i
+			for i < len(expr) && isNameChar(expr[i]) {
+				i++
+			}
+			// prefix:local and prefix:* but not the axis separator ::
+			if i+1 < len(expr) && expr[i] == ':' && expr[i+1] != ':' {
+				if expr[i+1] == '*' {
+					i += 2
+				} else if isNameStart(expr[i+1]) {
+					i++
+					for i < len(expr) && isNameChar(expr[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, xpathToken{xpathName, expr[start:i]})
+		default:
+			operator := ""
+			for _, candidate := range []string{"//", "::", "..", "!=", "<=", ">=", "/", "[", "]", "(", ")", "@", ",", "|", "=", "<", ">", ".", "*"} {
+				if strings.HasPrefix(expr[i:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("xpath %q: unexpected character %q", expr, c)
+			}
+			tokens = append(tokens, xpathToken{xpathOperator, operator})
+			i += len(operator)
+		}
+	}
+	return append(tokens, xpathToken{kind: xpathEOF}), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c == '-' || c == '.' || c >= '0' && c <= '9'
+}
+
+type xpathParser struct {
+	tokens []xpathToken
+	pos    int
+}
+
+func (p *xpathParser) peek() xpathToken {
+	return p.tokens[p.pos]
+}
+
+func (p *xpathParser) next() xpathToken {
+	token := p.tokens[p.pos]
+	if token.kind != xpathEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *xpathParser) isOperator(values ...string) bool {
+	token := p.peek()
+	if token.kind != xpathOperator {
+		return false
+	}
+	for _, value := range values {
+		if token.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *xpathParser) expect(value string) error {
+	if !p.isOperator(value) {
+		return fmt.Errorf("xpath: expected %q, found %q", value, p.peek().value)
+	}
+	p.next()
+	return nil
+}
+
+func (p *xpathParser) parseExpr() (xpathExpr, error) {
+	return p.parseBinary([][]string{{"or"}, {"and"}, {"=", "!="}, {"<", "<=", ">", ">="}}, 0)
+}
+
+func (p *xpathParser) parseBinary(levels [][]string, level int) (xpathExpr, error) {
+	if level == len(levels) {
+		return p.parseUnion()
+	}
+	left, err := p.parseBinary(levels, level+1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := p.peek()
+		matched := ""
+		for _, op := range levels[level] {
+			if (token.kind == xpathOperator || token.kind == xpathName) && token.value == op {
+				matched = op
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(levels, level+1)
+		if err != nil {
+			return nil, err
+		}
+		left = &xpathBinary{op: matched, left: left, right: right}
+	}
+}
+
+func (p *xpathParser) parseUnion() (xpathExpr, error) {
+	left, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("|") {
+		p.next()
+		right, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		left = &xpathUnion{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *xpathParser) parsePath() (xpathExpr, error) {
+	path := &xpathPath{}
+	token := p.peek()
+	switch {
+	case token.kind == xpathString:
+		p.next()
+		return &xpathLiteral{value: token.value}, nil
+	case token.kind == xpathNumber:
+		p.next()
+		number, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &xpathLiteral{value: number}, nil
+	case token.kind == xpathOperator && token.value == "(":
+		p.next()
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		path.filter = inner
+		if !p.isOperator("/", "//") {
+			return inner, nil
+		}
+	case token.kind == xpathName && p.tokens[p.pos+1].kind == xpathOperator && p.tokens[p.pos+1].value == "(" &&
+		token.value != "node" && token.value != "text" && token.value != "comment":
+		function, err := p.parseFunction()
+		if err != nil {
+			return nil, err
+		}
+		path.filter = function
+		if !p.isOperator("/", "//") {
+			return function, nil
+		}
+	case token.kind == xpathOperator && token.value == "/":
+		p.next()
+		path.absolute = true
+		if !p.startsStep() {
+			return path, nil
+		}
+		if err := p.parseStep(path); err != nil {
+			return nil, err
+		}
+	case token.kind == xpathOperator && token.value == "//":
+		path.absolute = true
+	default:
+		if err := p.parseStep(path); err != nil {
+			return nil, err
+		}
+	}
+	for p.isOperator("/", "//") {
+		if p.next().value == "//" {
+			path.steps = append(path.steps, &xpathStep{axis: axisDescendantOrSelf, test: testNode})
+		}
+		if err := p.parseStep(path); err != nil {
+			return nil, err
+		}
+	}
+	return path, nil
+}
+
+func (p *xpathParser) startsStep() bool {
+	token := p.peek()
+	return token.kind == xpathName || (token.kind == xpathOperator && (token.value == "@" || token.value == "*" || token.value == "." || token.value == ".."))
+}
+
+func (p *xpathParser) parseFunction() (xpathExpr, error) {
+	name := p.next().value
+	arity, ok := xpathFunctionArity[name]
+	if !ok {
+		return nil, fmt.Errorf("xpath: unsupported function %s()", name)
+	}
+	p.next()
+	function := &xpathFunction{name: name}
+	for !p.isOperator(")") {
+		if len(function.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		function.args = append(function.args, arg)
+	}
+	p.next()
+	if len(function.args) < arity[0] || len(function.args) > arity[1] {
+		return nil, fmt.Errorf("xpath: wrong number of arguments to %s()", name)
+	}
+	return function, nil
+}
+
+func (p *xpathParser) parseStep(path *xpathPath) error {
+	step := &xpathStep{axis: axisChild, test: testName}
+	token := p.next()
+	if token.kind == xpathOperator && token.value == "." {
+		path.steps = append(path.steps, &xpathStep{axis: axisSelf, test: testNode})
+		return nil
+	}
+	if token.kind == xpathOperator && token.value == ".." {
+		path.steps = append(path.steps, &xpathStep{axis: axisParent, test: testNode})
+		return nil
+	}
+	if token.kind == xpathOperator && token.value == "@" {
+		step.axis = axisAttribute
+		token = p.next()
+	} else if token.kind == xpathName && p.isOperator("::") {
+		axis, ok := xpathAxes[token.value]
+		if !ok {
+			return fmt.Errorf("xpath: unsupported axis %s", token.value)
+		}
+		step.axis = axis
+		p.next()
+		token = p.next()
+	}
+	switch {
+	case token.kind == xpathOperator && token.value == "*":
+		step.local = "*"
+	case token.kind == xpathName && p.isOperator("("):
+		switch token.value {
+		case "node":
+			step.test = testNode
+		case "text":
+			step.test = testText
+		case "comment":
+			step.test = testComment
+		default:
+			return fmt.Errorf("xpath: unsupported node test %s()", token.value)
+		}
+		p.next()
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+	case token.kind == xpathName:
+		name := xml.Name{Local: token.value}
+		if index := strings.IndexByte(token.value, ':'); index >= 0 {
+			name = xml.Name{Space: token.value[:index], Local: token.value[index+1:]}
+		}
+		step.prefix = name.Space
+		step.local = name.Local
+	default:
+		return fmt.Errorf("xpath: expected a node test, found %q", token.value)
+	}
+	for p.isOperator("[") {
+		p.next()
+		predicate, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+		step.predicates = append(step.predicates, predicate)
+	}
+	path.steps = append(path.steps, step)
+	return nil
+}
@@ -0,0 +1,92 @@
+package goMarklogicGo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func texts(nodes []*XMLNode) []string {
+	values := []string{}
+	for _, node := range nodes {
+		values = append(values, node.Text())
+	}
+	return values
+}
+
+func TestXPath(t *testing.T) {
+	doc, err := ParseXML([]byte(exampleXML))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	namespaces := map[string]string{
+		"c": "http://example.com/catalog",
+		"b": "http://example.com/book",
+	}
+	cases := []struct {
+		expr string
+		want []string
+	}{
+		{"/c:catalog/b:book/b:title", []string{"Moby Dick", "Walden"}},
+		{"//b:title", []string{"Moby Dick", "Walden"}},
+		{"//b:book[2]/b:title", []string{"Walden"}},
+		{"//b:book[last()]/b:year", []string{"1854"}},
+		{"//b:book[@id='1']/b:title/text()", []string{"Moby Dick"}},
+		{"//b:book[b:year > 1852]/b:title", []string{"Walden"}},
+		{"//b:book[b:title = 'Walden' or @c:status]/@id", []string{"1", "2"}},
+		{"//b:book[not(@c:status)]/@id", []string{"2"}},
+		{"//b:book[contains(b:title, 'Dick') and position() = 1]/b:year", []string{"1851"}},
+		{"//@c:status", []string{"available"}},
+		{"//b:year/../@id", []string{"1", "2"}},
+		{"//b:title | //b:year[. = '1851']", []string{"Moby Dick", "1851", "Walden"}},
+		{"/c:catalog/*[starts-with(b:title, 'W')]/@id", []string{"2"}},
+		{"//b:year/preceding-sibling::b:title", []string{"Moby Dick", "Walden"}},
+		{"//b:title[1]/ancestor::c:catalog/@xml:lang", []string{"en"}},
+		{"//comment()", []string{" catalog "}},
+		{"//title", []string{}},
+		{"count(//b:book)", nil},
+	}
+	for _, c := range cases {
+		nodes, err := doc.Find(c.expr, namespaces)
+		if c.want == nil {
+			if err == nil {
+				t.Errorf("Expected error evaluating %s", c.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error evaluating %s = %v", c.expr, err)
+			continue
+		}
+		if result := texts(nodes); !reflect.DeepEqual(result, c.want) {
+			t.Errorf("XPath %s Results = %+v, Want = %+v", c.expr, result, c.want)
+		}
+	}
+}
+
+func TestXPathDefaultNamespace(t *testing.T) {
+	doc, _ := ParseXML([]byte(exampleXML))
+	nodes, err := doc.Find("//book/title", map[string]string{"": "http://example.com/book"})
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if result := texts(nodes); !reflect.DeepEqual(result, []string{"Moby Dick", "Walden"}) {
+		t.Errorf("XPath Results = %+v", result)
+	}
+	book := doc.DocumentElement().Elements()[1]
+	nodes, _ = book.Find("title", map[string]string{"": "http://example.com/book"})
+	if result := texts(nodes); !reflect.DeepEqual(result, []string{"Walden"}) {
+		t.Errorf("Relative XPath Results = %+v", result)
+	}
+}
+
+func TestXPathCompileErrors(t *testing.T) {
+	for _, expr := range []string{"//a[", "a/", "//a[@b='c]", "foo(1)", "bogus::a", "//a]"} {
+		if _, err := CompileXPath(expr); err == nil {
+			t.Errorf("Expected compile error for %s", expr)
+		}
+	}
+	doc, _ := ParseXML([]byte(exampleXML))
+	if _, err := doc.Find("//x:a", nil); err == nil {
+		t.Errorf("Expected error for undeclared prefix")
+	}
+}
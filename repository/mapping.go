@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ryanjdew/go-marklogic-go/documents"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+// idPlaceholder is replaced in URI patterns with the value of the ID field
+const idPlaceholder = "{id}"
+
+// mapping describes how values of a struct type are stored as documents. It
+// is read from the struct tags of the type:
+//
+//	type User struct {
+//		_    struct{} `uri:"/users/{id}.json" collections:"users" permissions:"rest-reader:read,rest-writer:update"`
+//		ID   string   `json:"id" marklogic:"id"`
+//		Name string   `json:"name"`
+//	}
+//
+// The ID field is marked with marklogic:"id". The blank field carries the
+// URI pattern, a comma separated list of collections and a comma separated
+// list of role:capability permissions. The URI pattern defaults to
+// /<type name>/{id}.json and documents are stored as XML when it ends with
+// .xml, JSON otherwise.
+type mapping struct {
+	uriPattern  string
+	format      int
+	collections []string
+	permissions []documents.Permission
+	idField     []int
+}
+
+func newMapping(structType reflect.Type) (*mapping, error) {
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("repository type %v is not a struct", structType)
+	}
+	m := &mapping{uriPattern: "/" + strings.ToLower(structType.Name()) + "/" + idPlaceholder + ".json"}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Name == "_" {
+			if uri, ok := field.Tag.Lookup("uri"); ok {
+				m.uriPattern = uri
+			}
+			m.collections = splitTag(field.Tag.Get("collections"))
+			permissions, err := parsePermissions(field.Tag.Get("permissions"))
+			if err != nil {
+				return nil, err
+			}
+			m.permissions = permissions
+			continue
+		}
+		if field.Tag.Get("marklogic") != "id" {
+			continue
+		}
+		if m.idField != nil {
+			return nil, fmt.Errorf("repository type %v has more than one ID field", structType)
+		}
+		switch field.Type.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return nil, fmt.Errorf("ID field %s must be a string or an integer", field.Name)
+		}
+		m.idField = field.Index
+	}
+	if m.idField == nil {
+		return nil, fmt.Errorf(`repository type %v has no field tagged marklogic:"id"`, structType)
+	}
+	if strings.Count(m.uriPattern, idPlaceholder) != 1 {
+		return nil, fmt.Errorf("URI pattern %q must contain %s exactly once", m.uriPattern, idPlaceholder)
+	}
+	m.format = handle.JSON
+	if strings.HasSuffix(m.uriPattern, ".xml") {
+		m.format = handle.XML
+	}
+	return m, nil
+}
+
+// splitTag splits a comma separated tag value, dropping empty entries
+func splitTag(value string) []string {
+	values := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+// parsePermissions parses role:capability pairs, grouping the capabilities
+// of a role into one Permission
+func parsePermissions(value string) ([]documents.Permission, error) {
+	permissions := []documents.Permission{}
+	indexes := map[string]int{}
+	for _, entry := range splitTag(value) {
+		role, capability, found := strings.Cut(entry, ":")
+		if !found || role == "" || capability == "" {
+			return nil, fmt.Errorf("permission %q is not of the form role:capability", entry)
+		}
+		if index, ok := indexes[role]; ok {
+			permissions[index].Capability = append(permissions[index].Capability, capability)
+			continue
+		}
+		indexes[role] = len(permissions)
+		permissions = append(permissions, documents.Permission{RoleName: role, Capability: []string{capability}})
+	}
+	return permissions, nil
+}
+
+// uri returns the document URI for an ID
+func (m *mapping) uri(id any) (string, error) {
+	key := fmt.Sprint(id)
+	if key == "" {
+		return "", errors.New("document ID is empty")
+	}
+	return strings.Replace(m.uriPattern, idPlaceholder, key, 1), nil
+}
+
+// id returns the value of the ID field of value
+func (m *mapping) id(value any) (any, error) {
+	reflectValue := reflect.ValueOf(value)
+	for reflectValue.Kind() == reflect.Pointer {
+		if reflectValue.IsNil() {
+			return nil, errors.New("cannot save a nil value")
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	return reflectValue.FieldByIndex(m.idField).Interface(), nil
+}
+
+// directory returns the static part of the URI pattern up to its last / before
+// the ID
+func (m *mapping) directory() string {
+	prefix, _, _ := strings.Cut(m.uriPattern, idPlaceholder)
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+// metadata returns the metadata written with every document
+func (m *mapping) metadata() *documents.Metadata {
+	return &documents.Metadata{Collections: m.collections, Permissions: m.permissions}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/ryanjdew/go-marklogic-go/documents"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+type taggedUser struct {
+	_    struct{} `uri:"/users/{id}.xml" collections:"users, people" permissions:"rest-reader:read,rest-writer:read,rest-writer:update"`
+	ID   int      `xml:"id" marklogic:"id"`
+	Name string   `xml:"name"`
+}
+
+type untaggedUser struct {
+	Key string `json:"key" marklogic:"id"`
+}
+
+func TestNewMapping(t *testing.T) {
+	m, err := newMapping(reflect.TypeOf(&taggedUser{}))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	want := &mapping{
+		uriPattern:  "/users/{id}.xml",
+		format:      handle.XML,
+		collections: []string{"users", "people"},
+		permissions: []documents.Permission{
+			{RoleName: "rest-reader", Capability: []string{"read"}},
+			{RoleName: "rest-writer", Capability: []string{"read", "update"}},
+		},
+		idField: []int{1},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Mapping Results = %+v, Want = %+v", spew.Sdump(m), spew.Sdump(want))
+	}
+	id, _ := m.id(&taggedUser{ID: 7})
+	if uri, _ := m.uri(id); uri != "/users/7.xml" {
+		t.Errorf("URI Results = %+v, Want = %+v", uri, "/users/7.xml")
+	}
+	if directory := m.directory(); directory != "/users/" {
+		t.Errorf("Directory Results = %+v, Want = %+v", directory, "/users/")
+	}
+}
+
+func TestNewMappingDefaults(t *testing.T) {
+	m, err := newMapping(reflect.TypeOf(untaggedUser{}))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if m.uriPattern != "/untaggeduser/{id}.json" || m.format != handle.JSON || len(m.collections) != 0 {
+		t.Errorf("Mapping Results = %+v", spew.Sdump(m))
+	}
+}
+
+func TestNewMappingErrors(t *testing.T) {
+	type noID struct {
+		Name string
+	}
+	type badPattern struct {
+		_  struct{} `uri:"/users.json"`
+		ID string   `marklogic:"id"`
+	}
+	type badPermission struct {
+		_  struct{} `permissions:"rest-reader"`
+		ID string   `marklogic:"id"`
+	}
+	type badID struct {
+		ID float64 `marklogic:"id"`
+	}
+	for _, structType := range []reflect.Type{reflect.TypeOf(noID{}), reflect.TypeOf(badPattern{}), reflect.TypeOf(badPermission{}), reflect.TypeOf(badID{}), reflect.TypeOf("")} {
+		if _, err := newMapping(structType); err == nil {
+			t.Errorf("Mapping of %v Results = nil, Want = error", structType)
+		}
+	}
+}
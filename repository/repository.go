@@ -0,0 +1,214 @@
+// Package repository maps Go structs to MarkLogic documents. A Repository
+// saves, reads, deletes and searches values of one struct type, using the
+// struct tags of the type to decide the document URI, collections and
+// permissions.
+//
+// Example:
+//
+//	type User struct {
+//		_    struct{} `uri:"/users/{id}.json" collections:"users" permissions:"rest-reader:read,rest-writer:update"`
+//		ID   string   `json:"id" marklogic:"id"`
+//		Name string   `json:"name"`
+//	}
+//
+//	users, err := repository.New[User](client.Documents(), client.Search())
+//	err = users.Save(User{ID: "42", Name: "Ada"}, nil)
+//	user, err := users.Get("42", nil)
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/ryanjdew/go-marklogic-go/documents"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/search"
+	"github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// ErrNotFound is returned by Get when no document exists for the ID
+var ErrNotFound = errors.New("document not found")
+
+// Repository stores values of type T as documents
+type Repository[T any] struct {
+	documents *documents.Service
+	search    *search.Service
+	mapping   *mapping
+}
+
+// New creates a Repository for T, which must be a struct (or a pointer to
+// one) with a field tagged marklogic:"id".
+func New[T any](documentsService *documents.Service, searchService *search.Service) (*Repository[T], error) {
+	m, err := newMapping(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &Repository[T]{documents: documentsService, search: searchService, mapping: m}, nil
+}
+
+// URI returns the URI of the document stored for an ID
+func (r *Repository[T]) URI(id any) (string, error) {
+	return r.mapping.uri(id)
+}
+
+// Save writes value to the URI derived from its ID field with the
+// repository's collections and permissions
+//
+// Parameters:
+//
+//	value: Value to store
+//	transaction: Optional transaction to write within
+func (r *Repository[T]) Save(value T, transaction *util.Transaction) error {
+	id, err := r.mapping.id(value)
+	if err != nil {
+		return err
+	}
+	uri, err := r.mapping.uri(id)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if r.mapping.format == handle.XML {
+		content, err = xml.Marshal(value)
+	} else {
+		content, err = json.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	doc := &documents.DocumentDescription{
+		URI:      uri,
+		Content:  bytes.NewBuffer(content),
+		Format:   r.mapping.format,
+		Metadata: r.mapping.metadata(),
+	}
+	_, err = r.documents.WriteConcurrent([]*documents.DocumentDescription{doc}, 1, nil, transaction)
+	return err
+}
+
+// Get reads the value stored for an ID, returning ErrNotFound when there is
+// no such document
+//
+// Parameters:
+//
+//	id: Value of the ID field
+//	transaction: Optional transaction for consistent reads
+func (r *Repository[T]) Get(id any, transaction *util.Transaction) (T, error) {
+	var value T
+	uri, err := r.mapping.uri(id)
+	if err != nil {
+		return value, err
+	}
+	doc, err := r.documents.ReadDocument(uri, nil, transaction)
+	if err != nil {
+		var statusError *util.StatusError
+		if errors.As(err, &statusError) && statusError.StatusCode == http.StatusNotFound {
+			return value, ErrNotFound
+		}
+		return value, err
+	}
+	err = r.unmarshal(doc, &value)
+	return value, err
+}
+
+// Delete removes the document stored for an ID
+//
+// Parameters:
+//
+//	id: Value of the ID field
+//	transaction: Optional transaction to delete within
+func (r *Repository[T]) Delete(id any, transaction *util.Transaction) error {
+	uri, err := r.mapping.uri(id)
+	if err != nil {
+		return err
+	}
+	return r.documents.Delete([]string{uri}, nil, "", transaction, &handle.RawHandle{Format: handle.JSON})
+}
+
+// Find returns a page of the values matching a structured query, in search
+// result order. The query is limited to the repository's documents; an empty
+// query matches all of them.
+//
+// Parameters:
+//
+//	query: Structured query to match
+//	start: Position of the first result (1-based)
+//	pageLength: Maximum number of values to return
+//	transaction: Optional transaction for consistent results
+func (r *Repository[T]) Find(query search.Query, start int64, pageLength int64, transaction *util.Transaction) ([]T, error) {
+	response, err := r.execute(query, start, pageLength, transaction)
+	if err != nil {
+		return nil, err
+	}
+	values := []T{}
+	if len(response.Results) == 0 {
+		return values, nil
+	}
+	uris := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		uris = append(uris, result.URI)
+	}
+	docs, err := r.documents.ReadDocuments(uris, []string{"content"}, nil, transaction)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		var value T
+		if err := r.unmarshal(doc, &value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Count returns the number of values matching a structured query
+//
+// Parameters:
+//
+//	query: Structured query to match
+//	transaction: Optional transaction for consistent results
+func (r *Repository[T]) Count(query search.Query, transaction *util.Transaction) (int64, error) {
+	response, err := r.execute(query, 1, 0, transaction)
+	if err != nil {
+		return 0, err
+	}
+	return response.Total, nil
+}
+
+// execute runs query limited to the repository's collections, or to its
+// directory when it has none
+func (r *Repository[T]) execute(query search.Query, start int64, pageLength int64, transaction *util.Transaction) (*search.Response, error) {
+	var scope any = &search.DirectoryQuery{URIs: []string{r.mapping.directory()}, Infinite: true}
+	if len(r.mapping.collections) > 0 {
+		scope = &search.CollectionQuery{URIs: r.mapping.collections}
+	}
+	scoped := search.Query{Queries: []any{scope}}
+	if len(query.Queries) > 0 {
+		scoped.Queries = []any{&search.AndQuery{Queries: append([]any{scope}, query.Queries...)}}
+	}
+	queryHandle := &search.QueryHandle{Format: handle.XML}
+	queryHandle.Serialize(scoped)
+	response := &search.ResponseHandle{Format: handle.XML}
+	if err := r.search.StructuredSearch(queryHandle, start, pageLength, transaction, response); err != nil {
+		return nil, err
+	}
+	return response.Get(), nil
+}
+
+func (r *Repository[T]) unmarshal(doc *documents.DocumentDescription, value *T) error {
+	content := new(bytes.Buffer)
+	if doc.Content != nil {
+		if _, err := content.ReadFrom(doc.Content); err != nil {
+			return err
+		}
+	}
+	if r.mapping.format == handle.XML {
+		return xml.Unmarshal(content.Bytes(), value)
+	}
+	return json.Unmarshal(content.Bytes(), value)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/ryanjdew/go-marklogic-go/documents"
+	"github.com/ryanjdew/go-marklogic-go/search"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+type user struct {
+	_    struct{} `uri:"/users/{id}.json" collections:"users" permissions:"rest-reader:read"`
+	ID   string   `json:"id" marklogic:"id"`
+	Name string   `json:"name"`
+}
+
+func TestRepositorySaveGetDelete(t *testing.T) {
+	stored := map[string]string{}
+	var writeQuery string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri := r.URL.Query().Get("uri")
+		switch r.Method {
+		case "PUT":
+			writeQuery = r.URL.RawQuery
+			body, _ := io.ReadAll(r.Body)
+			stored[uri] = string(body)
+			w.WriteHeader(http.StatusCreated)
+		case "GET":
+			content, ok := stored[uri]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(content))
+		case "DELETE":
+			delete(stored, uri)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	users, err := New[user](documents.NewService(client), search.NewService(client))
+	if err != nil {
+		t.Fatalf("Error = %v", err)
+	}
+	if err := users.Save(user{ID: "42", Name: "Ada"}, nil); err != nil {
+		t.Fatalf("Save Error = %v", err)
+	}
+	if stored["/users/42.json"] != `{"id":"42","name":"Ada"}` {
+		t.Errorf("Save Results = %+v", spew.Sdump(stored))
+	}
+	if wantQuery := "uri=%2Fusers%2F42.json&collection=users&perm:rest-reader=read"; writeQuery != wantQuery {
+		t.Errorf("Save Request = %+v, Want = %+v", writeQuery, wantQuery)
+	}
+	got, err := users.Get("42", nil)
+	want := user{ID: "42", Name: "Ada"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get Results = %+v, %v, Want = %+v", spew.Sdump(got), err, spew.Sdump(want))
+	}
+	if err := users.Delete("42", nil); err != nil {
+		t.Fatalf("Delete Error = %v", err)
+	}
+	if _, err := users.Get("42", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get Error = %v, Want = %v", err, ErrNotFound)
+	}
+	if err := users.Save(user{Name: "Nobody"}, nil); err == nil {
+		t.Errorf("Save without ID Results = nil, Want = error")
+	}
+}
+
+func TestRepositoryFindAndCount(t *testing.T) {
+	var queries []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			body, _ := io.ReadAll(r.Body)
+			queries = append(queries, string(body))
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<search:response xmlns:search="http://marklogic.com/appservices/search" total="2" start="1" page-length="10">
+	<search:result index="1" uri="/users/2.json"/>
+	<search:result index="2" uri="/users/1.json"/>
+</search:response>`))
+			return
+		}
+		writer := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+		for _, id := range r.URL.Query()["uri"] {
+			part, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}, "Content-Disposition": {`attachment; filename="` + id + `"; category=content; format=json`}})
+			name := strings.TrimSuffix(strings.TrimPrefix(id, "/users/"), ".json")
+			json.NewEncoder(part).Encode(user{ID: name, Name: "user " + name})
+		}
+		writer.Close()
+	}))
+	defer server.Close()
+	users, _ := New[*user](documents.NewService(client), search.NewService(client))
+	query := search.Query{Queries: []any{&search.TermQuery{Terms: []string{"user"}}}}
+	found, err := users.Find(query, 1, 10, nil)
+	if err != nil {
+		t.Fatalf("Find Error = %v", err)
+	}
+	want := []*user{{ID: "2", Name: "user 2"}, {ID: "1", Name: "user 1"}}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("Find Results = %+v, Want = %+v", spew.Sdump(found), spew.Sdump(want))
+	}
+	count, err := users.Count(search.Query{}, nil)
+	if err != nil || count != 2 {
+		t.Errorf("Count Results = %+v, %v, Want = %+v", count, err, 2)
+	}
+	wantQueries := []string{
+		`<query xmlns="http://marklogic.com/appservices/search"><and-query xmlns="http://marklogic.com/appservices/search"><collection-query xmlns="http://marklogic.com/appservices/search"><uri xmlns="http://marklogic.com/appservices/search">users</uri></collection-query><term-query xmlns="http://marklogic.com/appservices/search"><text xmlns="http://marklogic.com/appservices/search">user</text></term-query></and-query></query>`,
+		`<query xmlns="http://marklogic.com/appservices/search"><collection-query xmlns="http://marklogic.com/appservices/search"><uri xmlns="http://marklogic.com/appservices/search">users</uri></collection-query></query>`,
+	}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Errorf("Find Queries = %+v, Want = %+v", spew.Sdump(queries), spew.Sdump(wantQueries))
+	}
+}
@@ -0,0 +1,601 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Range operators of a range query
+const (
+	RangeLT = "LT"
+	RangeLE = "LE"
+	RangeGT = "GT"
+	RangeGE = "GE"
+	RangeEQ = "EQ"
+	RangeNE = "NE"
+)
+
+// Fragment scopes of range, value, word and container queries
+const (
+	FragmentScopeDocuments  = "documents"
+	FragmentScopeProperties = "properties"
+)
+
+// Index identifies the element, attribute, JSON property, field or path a
+// query is applied to
+type Index struct {
+	kind      string
+	element   QueryElement
+	attribute QueryAttribute
+	name      string
+}
+
+// Element returns the Index of an element
+func Element(namespace string, name string) Index {
+	return Index{kind: "element", element: QueryElement{Namespace: namespace, Local: name}}
+}
+
+// Attribute returns the Index of an attribute of an element
+func Attribute(elementNamespace string, elementName string, namespace string, name string) Index {
+	return Index{
+		kind:      "attribute",
+		element:   QueryElement{Namespace: elementNamespace, Local: elementName},
+		attribute: QueryAttribute{Namespace: namespace, Local: name},
+	}
+}
+
+// JSONProperty returns the Index of a JSON property
+func JSONProperty(name string) Index {
+	return Index{kind: "json-property", name: name}
+}
+
+// Field returns the Index of a field
+func Field(name string) Index {
+	return Index{kind: "field", name: name}
+}
+
+// Path returns the Index of a path range index
+func Path(path string) Index {
+	return Index{kind: "path", name: path}
+}
+
+// Region is a Point, Box, Circle or Polygon matched by a geospatial query
+type Region interface {
+	region()
+}
+
+func (*Point) region()   {}
+func (*Box) region()     {}
+func (*Circle) region()  {}
+func (*Polygon) region() {}
+
+// QueryBuilder builds a structured query. The functions of this file return
+// a QueryBuilder for each kind of query, which can be refined with its
+// methods and nested in other queries; Build checks the whole query and
+// returns it as a Query.
+type QueryBuilder struct {
+	name     string
+	query    any
+	index    Index
+	indexes  []Index
+	regions  []Region
+	children []*QueryBuilder
+	err      error
+}
+
+func newQueryBuilder(name string, query any, children ...*QueryBuilder) *QueryBuilder {
+	return &QueryBuilder{name: name, query: query, children: children}
+}
+
+// And matches documents matching all of queries
+func And(queries ...*QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("and-query", &AndQuery{}, queries...)
+}
+
+// Or matches documents matching any of queries
+func Or(queries ...*QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("or-query", &OrQuery{}, queries...)
+}
+
+// Not matches documents not matching query
+func Not(query *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("not-query", &NotQuery{}, query)
+}
+
+// AndNot matches documents matching positive but not negative
+func AndNot(positive *QueryBuilder, negative *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("and-not-query", &AndNotQuery{}, positive, negative)
+}
+
+// NotIn matches the positive query where it does not overlap the negative one
+func NotIn(positive *QueryBuilder, negative *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("not-in-query", &NotInQuery{}, positive, negative)
+}
+
+// Near matches documents where queries match within distance words of each
+// other. A distance of 0 uses the server default.
+func Near(distance int64, queries ...*QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("near-query", &NearQuery{Distance: distance}, queries...)
+}
+
+// Boost matches documents matching matching, raising the score of those also
+// matching boosting
+func Boost(matching *QueryBuilder, boosting *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("boost-query", &BoostQuery{}, matching, boosting)
+}
+
+// Properties matches documents whose properties match query
+func Properties(query *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("properties-query", &PropertiesQuery{}, query)
+}
+
+// DocumentFragment matches documents whose content matches query
+func DocumentFragment(query *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("document-fragment-query", &DocumentFragmentQuery{}, query)
+}
+
+// Locks matches documents whose locks match query
+func Locks(query *QueryBuilder) *QueryBuilder {
+	return newQueryBuilder("locks-query", &LocksQuery{}, query)
+}
+
+// Term matches documents containing terms anywhere
+func Term(terms ...string) *QueryBuilder {
+	return newQueryBuilder("term-query", &TermQuery{Terms: terms})
+}
+
+// Document matches the documents with the given URIs
+func Document(uris ...string) *QueryBuilder {
+	return newQueryBuilder("document-query", &DocumentQuery{URIs: uris})
+}
+
+// Collection matches the documents in any of collections
+func Collection(collections ...string) *QueryBuilder {
+	return newQueryBuilder("collection-query", &CollectionQuery{URIs: collections})
+}
+
+// Directory matches the documents directly in any of directories, or at
+// any depth below them with Infinite
+func Directory(directories ...string) *QueryBuilder {
+	return newQueryBuilder("directory-query", &DirectoryQuery{URIs: directories})
+}
+
+// Container matches documents where query matches within an element or JSON
+// property
+func Container(index Index, query *QueryBuilder) *QueryBuilder {
+	qb := newQueryBuilder("container-query", &ContainerQuery{}, query)
+	qb.index = index
+	return qb
+}
+
+// Range compares the values of a range index with value using operator,
+// one of the Range constants. The type of the index must be set with Type.
+func Range(index Index, operator string, value string) *QueryBuilder {
+	qb := newQueryBuilder("range-query", &RangeQuery{RangeOperator: operator, Value: value})
+	qb.index = index
+	return qb
+}
+
+// Value matches documents where the value of index is one of values
+func Value(index Index, values ...string) *QueryBuilder {
+	qb := newQueryBuilder("value-query", &ValueQuery{Text: values})
+	qb.index = index
+	return qb
+}
+
+// Word matches documents where index contains any of words
+func Word(index Index, words ...string) *QueryBuilder {
+	qb := newQueryBuilder("word-query", &WordQuery{Text: words})
+	qb.index = index
+	return qb
+}
+
+// GeoElem matches documents where the points of an element fall in any of
+// regions
+func GeoElem(index Index, regions ...Region) *QueryBuilder {
+	qb := newQueryBuilder("geo-elem-query", &GeoElemQuery{})
+	qb.index, qb.regions = index, regions
+	return qb
+}
+
+// GeoElemPair matches documents where the points of latitude and longitude
+// child elements fall in any of regions
+func GeoElemPair(latitude Index, longitude Index, regions ...Region) *QueryBuilder {
+	qb := newQueryBuilder("geo-elem-pair-query", &GeoElemPairQuery{})
+	qb.indexes, qb.regions = []Index{latitude, longitude}, regions
+	return qb
+}
+
+// GeoAttrPair matches documents where the points of latitude and longitude
+// attributes of an element fall in any of regions
+func GeoAttrPair(latitude Index, longitude Index, regions ...Region) *QueryBuilder {
+	qb := newQueryBuilder("geo-attr-pair-query", &GeoAttrPairQuery{})
+	qb.indexes, qb.regions = []Index{latitude, longitude}, regions
+	return qb
+}
+
+// GeoPath matches documents where the points of a geospatial path index fall
+// in any of regions
+func GeoPath(index Index, regions ...Region) *QueryBuilder {
+	qb := newQueryBuilder("geo-path-query", &GeoPathQuery{})
+	qb.index, qb.regions = index, regions
+	return qb
+}
+
+// unsupported records that a method does not apply to the query
+func (qb *QueryBuilder) unsupported(method string) *QueryBuilder {
+	if qb.err == nil {
+		qb.err = errors.New(method + " is not supported by " + qb.name)
+	}
+	return qb
+}
+
+// Weight sets the weight of a term, value or word query
+func (qb *QueryBuilder) Weight(weight float64) *QueryBuilder {
+	switch query := qb.query.(type) {
+	case *TermQuery:
+		query.Weight = weight
+	case *ValueQuery:
+		query.Weight = weight
+	case *WordQuery:
+		query.Weight = weight
+	case *NearQuery:
+		query.DistanceWeight = weight
+	default:
+		return qb.unsupported("weight")
+	}
+	return qb
+}
+
+// Ordered requires the queries of an and or near query to match in order
+func (qb *QueryBuilder) Ordered() *QueryBuilder {
+	switch query := qb.query.(type) {
+	case *AndQuery:
+		query.Ordered = true
+	case *NearQuery:
+		query.Ordered = true
+	default:
+		return qb.unsupported("ordered")
+	}
+	return qb
+}
+
+// Infinite makes a directory query match documents at any depth
+func (qb *QueryBuilder) Infinite() *QueryBuilder {
+	query, ok := qb.query.(*DirectoryQuery)
+	if !ok {
+		return qb.unsupported("infinite")
+	}
+	query.Infinite = true
+	return qb
+}
+
+// Type sets the type of the index of a range query, such as xs:int
+func (qb *QueryBuilder) Type(dataType string) *QueryBuilder {
+	query, ok := qb.query.(*RangeQuery)
+	if !ok {
+		return qb.unsupported("type")
+	}
+	query.Type = dataType
+	return qb
+}
+
+// Collation sets the collation of the string index of a range query
+func (qb *QueryBuilder) Collation(collation string) *QueryBuilder {
+	query, ok := qb.query.(*RangeQuery)
+	if !ok {
+		return qb.unsupported("collation")
+	}
+	query.Collation = collation
+	return qb
+}
+
+// FragmentScope restricts a range, value, word or container query to
+// documents or properties, one of the FragmentScope constants
+func (qb *QueryBuilder) FragmentScope(scope string) *QueryBuilder {
+	switch query := qb.query.(type) {
+	case *RangeQuery:
+		query.FragmentScope = scope
+	case *ValueQuery:
+		query.FragmentScope = scope
+	case *WordQuery:
+		query.FragmentScope = scope
+	case *ContainerQuery:
+		query.FragmentScope = scope
+	default:
+		return qb.unsupported("fragment-scope")
+	}
+	return qb
+}
+
+// Options sets the range options of a range query, the term options of a
+// value or word query or the geo options of a geospatial query
+func (qb *QueryBuilder) Options(options ...string) *QueryBuilder {
+	switch query := qb.query.(type) {
+	case *RangeQuery:
+		query.RangeOptions = options
+	case *ValueQuery:
+		query.TermOptions = options
+	case *WordQuery:
+		query.TermOptions = options
+	case *GeoElemQuery:
+		query.GeoOptions = options
+	case *GeoElemPairQuery:
+		query.GeoOptions = options
+	case *GeoAttrPairQuery:
+		query.GeoOptions = options
+	case *GeoPathQuery:
+		query.GeoOptions = options
+	default:
+		return qb.unsupported("options")
+	}
+	return qb
+}
+
+// Parent sets the parent element of the element or elements of a
+// geo-elem-query or geo-elem-pair-query
+func (qb *QueryBuilder) Parent(namespace string, name string) *QueryBuilder {
+	parent := QueryParent{Namespace: namespace, Local: name}
+	switch query := qb.query.(type) {
+	case *GeoElemQuery:
+		query.Parent = parent
+	case *GeoElemPairQuery:
+		query.Parent = parent
+	default:
+		return qb.unsupported("parent")
+	}
+	return qb
+}
+
+// Build validates the query and returns it as a Query
+func (qb *QueryBuilder) Build() (Query, error) {
+	query, err := qb.build(nil)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{Queries: []any{query}}, nil
+}
+
+// fragmentQueries change the fragment searched by the queries they contain
+// and cannot be nested in one another
+var fragmentQueries = map[string]bool{
+	"properties-query":        true,
+	"document-fragment-query": true,
+	"locks-query":             true,
+}
+
+// build validates the query within its ancestors and returns a copy of it
+// with its children, so a QueryBuilder can be built and nested repeatedly
+func (qb *QueryBuilder) build(ancestors []*QueryBuilder) (any, error) {
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	if fragmentQueries[qb.name] {
+		for _, ancestor := range ancestors {
+			if fragmentQueries[ancestor.name] {
+				return nil, errors.New(qb.name + " cannot be nested in " + ancestor.name)
+			}
+		}
+	}
+	query := reflect.New(reflect.TypeOf(qb.query).Elem())
+	query.Elem().Set(reflect.ValueOf(qb.query).Elem())
+	if err := qb.validate(query.Interface()); err != nil {
+		return nil, err
+	}
+	ancestors = append(ancestors, qb)
+	children := make([]any, len(qb.children))
+	for i, child := range qb.children {
+		if child == nil {
+			return nil, errors.New(qb.name + " has a nil query")
+		}
+		built, err := child.build(ancestors)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = built
+	}
+	switch typed := query.Interface().(type) {
+	case *AndNotQuery:
+		typed.PositiveQuery.Queries, typed.NegativeQuery.Queries = children[:1], children[1:]
+	case *NotInQuery:
+		typed.PositiveQuery.Queries, typed.NegativeQuery.Queries = children[:1], children[1:]
+	case *BoostQuery:
+		typed.MatchingQuery.Queries, typed.BoostingQuery.Queries = children[:1], children[1:]
+	default:
+		if queries := query.Elem().FieldByName("Queries"); queries.IsValid() {
+			queries.Set(reflect.ValueOf(children))
+		}
+	}
+	return query.Interface(), nil
+}
+
+// validate checks the required fields of query and sets its index and regions
+func (qb *QueryBuilder) validate(query any) error {
+	switch typed := query.(type) {
+	case *NearQuery:
+		if len(qb.children) == 0 {
+			return errors.New("near-query requires at least one query")
+		}
+		if typed.Distance < 0 {
+			return errors.New("near-query distance cannot be negative")
+		}
+	case *TermQuery:
+		if len(typed.Terms) == 0 {
+			return errors.New("term-query requires at least one term")
+		}
+	case *DocumentQuery, *CollectionQuery, *DirectoryQuery:
+		if reflect.ValueOf(typed).Elem().FieldByName("URIs").Len() == 0 {
+			return errors.New(qb.name + " requires at least one URI")
+		}
+	case *ContainerQuery:
+		switch qb.index.kind {
+		case "element":
+			typed.Element = qb.index.element
+		case "json-property":
+			typed.JSONKey = qb.index.name
+		default:
+			return qb.indexError()
+		}
+		return validateFragmentScope(qb.name, typed.FragmentScope)
+	case *RangeQuery:
+		switch qb.index.kind {
+		case "element", "attribute":
+			typed.Element, typed.Attribute = qb.index.element, qb.index.attribute
+		case "json-property":
+			typed.JSONKey = qb.index.name
+		case "field":
+			typed.Field = FieldReference{Name: qb.index.name}
+		case "path":
+			typed.PathIndex = qb.index.name
+		default:
+			return qb.indexError()
+		}
+		if typed.Type == "" {
+			return errors.New("range-query requires the type of its index")
+		}
+		switch strings.ToUpper(typed.RangeOperator) {
+		case RangeLT, RangeLE, RangeGT, RangeGE, RangeEQ, RangeNE:
+			typed.RangeOperator = strings.ToUpper(typed.RangeOperator)
+		default:
+			return errors.New("invalid range operator: " + typed.RangeOperator)
+		}
+		return validateFragmentScope(qb.name, typed.FragmentScope)
+	case *ValueQuery:
+		if len(typed.Text) == 0 {
+			return errors.New("value-query requires at least one value")
+		}
+		if err := qb.setTermIndex(&typed.Element, &typed.Attribute, &typed.JSONKey, &typed.Field); err != nil {
+			return err
+		}
+		return validateFragmentScope(qb.name, typed.FragmentScope)
+	case *WordQuery:
+		if len(typed.Text) == 0 {
+			return errors.New("word-query requires at least one word")
+		}
+		if err := qb.setTermIndex(&typed.Element, &typed.Attribute, &typed.JSONKey, &typed.Field); err != nil {
+			return err
+		}
+		return validateFragmentScope(qb.name, typed.FragmentScope)
+	case *GeoElemQuery:
+		if qb.index.kind != "element" {
+			return qb.indexError()
+		}
+		typed.Element = qb.index.element
+		return qb.setRegions(&typed.Points, &typed.Boxes, &typed.Circles, &typed.Polygons)
+	case *GeoElemPairQuery:
+		if qb.indexes[0].kind != "element" || qb.indexes[1].kind != "element" {
+			return errors.New("geo-elem-pair-query requires element latitude and longitude indexes")
+		}
+		typed.Lat = Lat{Namespace: qb.indexes[0].element.Namespace, Local: qb.indexes[0].element.Local}
+		typed.Lon = Lon{Namespace: qb.indexes[1].element.Namespace, Local: qb.indexes[1].element.Local}
+		return qb.setRegions(&typed.Points, &typed.Boxes, &typed.Circles, &typed.Polygons)
+	case *GeoAttrPairQuery:
+		latitude, longitude := qb.indexes[0], qb.indexes[1]
+		if latitude.kind != "attribute" || longitude.kind != "attribute" {
+			return errors.New("geo-attr-pair-query requires attribute latitude and longitude indexes")
+		}
+		if latitude.element != longitude.element {
+			return errors.New("geo-attr-pair-query requires attributes of the same element")
+		}
+		typed.Parent = QueryParent{Namespace: latitude.element.Namespace, Local: latitude.element.Local}
+		typed.Lat = Lat{Namespace: latitude.attribute.Namespace, Local: latitude.attribute.Local}
+		typed.Lon = Lon{Namespace: longitude.attribute.Namespace, Local: longitude.attribute.Local}
+		return qb.setRegions(&typed.Points, &typed.Boxes, &typed.Circles, &typed.Polygons)
+	case *GeoPathQuery:
+		if qb.index.kind != "path" {
+			return qb.indexError()
+		}
+		typed.PathIndex = qb.index.name
+		return qb.setRegions(&typed.Points, &typed.Boxes, &typed.Circles, &typed.Polygons)
+	}
+	return nil
+}
+
+func (qb *QueryBuilder) indexError() error {
+	if qb.index.kind == "" {
+		return errors.New(qb.name + " requires an index")
+	}
+	return errors.New(qb.name + " does not support a " + qb.index.kind + " index")
+}
+
+// setTermIndex sets the index of a value or word query
+func (qb *QueryBuilder) setTermIndex(element *QueryElement, attribute *QueryAttribute, jsonKey *string, field *FieldReference) error {
+	switch qb.index.kind {
+	case "element", "attribute":
+		*element, *attribute = qb.index.element, qb.index.attribute
+	case "json-property":
+		*jsonKey = qb.index.name
+	case "field":
+		*field = FieldReference{Name: qb.index.name}
+	default:
+		return qb.indexError()
+	}
+	return nil
+}
+
+// setRegions validates the regions of a geospatial query and sorts them
+// into the query's points, boxes, circles and polygons
+func (qb *QueryBuilder) setRegions(points *[]*Point, boxes *[]*Box, circles *[]*Circle, polygons *[]*Polygon) error {
+	if len(qb.regions) == 0 {
+		return errors.New(qb.name + " requires at least one region")
+	}
+	*points, *boxes, *circles, *polygons = nil, nil, nil, nil
+	for _, region := range qb.regions {
+		switch typed := region.(type) {
+		case *Point:
+			if err := validatePoint(typed); err != nil {
+				return err
+			}
+			*points = append(*points, typed)
+		case *Box:
+			if typed == nil || typed.South > typed.North || !validLatitude(typed.South) || !validLatitude(typed.North) || !validLongitude(typed.West) || !validLongitude(typed.East) {
+				return errors.New(qb.name + " has an invalid box")
+			}
+			*boxes = append(*boxes, typed)
+		case *Circle:
+			if typed == nil || typed.Radius < 0 {
+				return errors.New(qb.name + " has an invalid circle")
+			}
+			if err := validatePoint(&typed.Point); err != nil {
+				return err
+			}
+			*circles = append(*circles, typed)
+		case *Polygon:
+			if typed == nil || len(typed.Points) < 3 {
+				return errors.New(qb.name + " has a polygon with fewer than 3 points")
+			}
+			for _, point := range typed.Points {
+				if err := validatePoint(point); err != nil {
+					return err
+				}
+			}
+			*polygons = append(*polygons, typed)
+		default:
+			return errors.New(qb.name + " has a nil region")
+		}
+	}
+	return nil
+}
+
+func validatePoint(point *Point) error {
+	if point == nil || !validLatitude(point.Latitude) || !validLongitude(point.Longitude) {
+		return errors.New("invalid point: latitude must be within ±90 and longitude within ±180")
+	}
+	return nil
+}
+
+func validLatitude(latitude float64) bool {
+	return latitude >= -90 && latitude <= 90
+}
+
+func validLongitude(longitude float64) bool {
+	return longitude >= -180 && longitude <= 180
+}
+
+func validateFragmentScope(name string, scope string) error {
+	if scope != "" && scope != FragmentScopeDocuments && scope != FragmentScopeProperties {
+		return errors.New(name + " has an invalid fragment scope: " + scope)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+func TestQueryBuilderXML(t *testing.T) {
+	query, err := And(
+		Range(JSONProperty("price"), "lt", "10").Type("xs:decimal"),
+		Or(Word(Element("http://example.com", "title"), "star").Weight(2), Collection("books")),
+		Not(Directory("/drafts/").Infinite()),
+	).Ordered().Build()
+	if err != nil {
+		t.Fatalf("Build Error = %v", err)
+	}
+	want := `<query xmlns="http://marklogic.com/appservices/search"><and-query xmlns="http://marklogic.com/appservices/search"><ordered xmlns="http://marklogic.com/appservices/search">true</ordered>` +
+		`<range-query xmlns="http://marklogic.com/appservices/search" type="xs:decimal"><json-key xmlns="http://marklogic.com/appservices/search">price</json-key><value xmlns="http://marklogic.com/appservices/search">10</value><range-operator xmlns="http://marklogic.com/appservices/search">LT</range-operator></range-query>` +
+		`<or-query xmlns="http://marklogic.com/appservices/search"><word-query xmlns="http://marklogic.com/appservices/search"><element xmlns="http://marklogic.com/appservices/search" ns="http://example.com" name="title"></element><text xmlns="http://marklogic.com/appservices/search">star</text><weight xmlns="http://marklogic.com/appservices/search">2</weight></word-query>` +
+		`<collection-query xmlns="http://marklogic.com/appservices/search"><uri xmlns="http://marklogic.com/appservices/search">books</uri></collection-query></or-query>` +
+		`<not-query xmlns="http://marklogic.com/appservices/search"><directory-query xmlns="http://marklogic.com/appservices/search"><uri xmlns="http://marklogic.com/appservices/search">/drafts/</uri><infinite xmlns="http://marklogic.com/appservices/search">true</infinite></directory-query></not-query>` +
+		`</and-query></query>`
+	qh := QueryHandle{Format: handle.XML}
+	qh.Serialize(query)
+	if result := qh.Serialized(); result != want {
+		t.Errorf("Query Results = %+v, Want = %+v", result, want)
+	}
+}
+
+func TestQueryBuilderJSON(t *testing.T) {
+	builder := AndNot(
+		Container(JSONProperty("author"), Value(Field("name"), "Twain")),
+		GeoPath(Path("/location"), &Circle{Radius: 5, Point: Point{Latitude: 38.9, Longitude: -77}}),
+	)
+	want := `{"query":{"queries":[{"and-not-query":{"positive-query":{"queries":[{"container-query":{"json-key":"author","queries":[{"value-query":{"field":{"name":"name"},"text":["Twain"]}}]}}]},` +
+		`"negative-query":{"queries":[{"geo-path-query":{"path-index":"/location","circle":[{"radius":5,"point":{"latitude":38.9,"longitude":-77}}]}}]}}}]}}`
+	// a builder can be built again, and serializing one query leaves the other unchanged
+	for i := 0; i < 2; i++ {
+		query, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Build Error = %v", err)
+		}
+		qh := QueryHandle{Format: handle.JSON}
+		qh.Serialize(query)
+		if result := strings.TrimSpace(qh.Serialized()); result != want {
+			t.Errorf("Query Results = %+v, Want = %+v", result, want)
+		}
+	}
+}
+
+func TestQueryBuilderValidation(t *testing.T) {
+	cases := map[string]*QueryBuilder{
+		"invalid range operator: LTE":                       Range(JSONProperty("price"), "LTE", "10").Type("xs:int"),
+		"range-query requires the type of its index":        Range(JSONProperty("price"), RangeLT, "10"),
+		"value-query does not support a path index":         Value(Path("/a"), "b"),
+		"container-query requires an index":                 Container(Index{}, Term("a")),
+		"word-query requires at least one word":             Word(JSONProperty("title")),
+		"weight is not supported by collection-query":       And(Collection("a").Weight(2)),
+		"properties-query cannot be nested in locks-query":  Locks(And(Properties(Term("a")))),
+		"or-query has a nil query":                          Or(Term("a"), nil),
+		"range-query has an invalid fragment scope: values": Range(Field("f"), RangeEQ, "a").Type("xs:string").FragmentScope("values"),
+		"geo-elem-query requires at least one region":       GeoElem(Element("", "point")),
+		"geo-attr-pair-query requires attributes of the same element": GeoAttrPair(
+			Attribute("", "a", "", "lat"), Attribute("", "b", "", "lon"), &Point{}),
+		"invalid point: latitude must be within ±90 and longitude within ±180": GeoElemPair(
+			Element("", "lat"), Element("", "lon"), &Polygon{Points: []*Point{{}, {Latitude: 91}, {}}}),
+	}
+	for want, builder := range cases {
+		if _, err := builder.Build(); err == nil || err.Error() != want {
+			t.Errorf("Build Error = %v, Want = %+v", err, want)
+		}
+	}
+}
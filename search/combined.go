@@ -0,0 +1,291 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+// RawQuery is a query serialized as XML or JSON, such as a cts query, that
+// is included in a CombinedQuery as is. Its format must match the format the
+// CombinedQuery is serialized in.
+type RawQuery struct {
+	Content string
+}
+
+// MarshalXML writes the XML content of the query
+func (rq *RawQuery) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	decoder := xml.NewDecoder(strings.NewReader(rq.Content))
+	tokens := []xml.Token{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+	if err := encodeXMLTokens(e, tokens); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// UnmarshalXML reads the element of the query
+func (rq *RawQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	tokens := []xml.Token{}
+	var token xml.Token = start
+	for depth := 0; ; {
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+		if depth == 0 {
+			break
+		}
+		var err error
+		if token, err = d.Token(); err != nil {
+			return err
+		}
+	}
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	if err := encodeXMLTokens(encoder, tokens); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	rq.Content = buffer.String()
+	return nil
+}
+
+// encodeXMLTokens encodes decoded tokens again
+func encodeXMLTokens(e *xml.Encoder, tokens []xml.Token) error {
+	prefixes := contentPrefixes(tokens)
+	for _, token := range tokens {
+		if token = xmlToken(token, prefixes); token != nil {
+			if err := e.EncodeToken(token); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// contentPrefixes returns the declared namespace prefixes that are used in
+// text or attribute values, such as QNames and xsi:type values
+func contentPrefixes(tokens []xml.Token) map[string]bool {
+	declared := map[string]bool{}
+	content := []string{}
+	for _, token := range tokens {
+		switch typed := token.(type) {
+		case xml.StartElement:
+			for _, attribute := range typed.Attr {
+				if attribute.Name.Space == "xmlns" {
+					declared[attribute.Name.Local] = true
+				} else if attribute.Name.Space != "" || attribute.Name.Local != "xmlns" {
+					content = append(content, attribute.Value)
+				}
+			}
+		case xml.CharData:
+			content = append(content, string(typed))
+		}
+	}
+	used := map[string]bool{}
+	for prefix := range declared {
+		for _, value := range content {
+			if strings.Contains(value, prefix+":") {
+				used[prefix] = true
+				break
+			}
+		}
+	}
+	return used
+}
+
+// xmlToken prepares a decoded token to be encoded again. Default namespace
+// declarations are dropped as the encoder declares the namespaces of the
+// elements it writes, prefix declarations are kept only for prefixes used in
+// content, and processing instructions and directives are skipped.
+func xmlToken(token xml.Token, prefixes map[string]bool) xml.Token {
+	switch typed := token.(type) {
+	case xml.StartElement:
+		element := typed.Copy()
+		attributes := element.Attr[:0]
+		for _, attribute := range element.Attr {
+			switch {
+			case attribute.Name.Space == "xmlns":
+				if prefixes[attribute.Name.Local] {
+					attributes = append(attributes, xml.Attr{Name: xml.Name{Local: "xmlns:" + attribute.Name.Local}, Value: attribute.Value})
+				}
+			case attribute.Name.Space != "" || attribute.Name.Local != "xmlns":
+				attributes = append(attributes, attribute)
+			}
+		}
+		element.Attr = attributes
+		return element
+	case xml.ProcInst, xml.Directive:
+		return nil
+	}
+	return xml.CopyToken(token)
+}
+
+// MarshalJSON writes the JSON content of the query
+func (rq *RawQuery) MarshalJSON() ([]byte, error) {
+	return []byte(rq.Content), nil
+}
+
+// UnmarshalJSON reads the JSON content of the query
+func (rq *RawQuery) UnmarshalJSON(data []byte) error {
+	rq.Content = string(data)
+	return nil
+}
+
+// combinedQueryXML leaves out the empty parts of a CombinedQuery
+type combinedQueryXML struct {
+	XMLName         xml.Name  `xml:"http://marklogic.com/appservices/search search"`
+	StructuredQuery *Query    `xml:"http://marklogic.com/appservices/search query,omitempty"`
+	QText           []string  `xml:"http://marklogic.com/appservices/search qtext"`
+	SPARQL          string    `xml:"http://marklogic.com/appservices/search sparql,omitempty"`
+	CTSQuery        *RawQuery `xml:",any,omitempty"`
+	Options         *Options  `xml:"http://marklogic.com/appservices/search options,omitempty"`
+}
+
+// combinedQueryJSON is the JSON representation of a CombinedQuery
+type combinedQueryJSON struct {
+	Search struct {
+		StructuredQuery json.RawMessage `json:"query,omitempty"`
+		QText           json.RawMessage `json:"qtext,omitempty"`
+		SPARQL          string          `json:"sparql,omitempty"`
+		CTSQuery        *RawQuery       `json:"ctsquery,omitempty"`
+		Options         *Options        `json:"options,omitempty"`
+	} `json:"search"`
+}
+
+// CombinedQueryHandle is a handle that places the results into
+// a CombinedQuery struct
+type CombinedQueryHandle struct {
+	*bytes.Buffer
+	Format        int
+	CombinedQuery CombinedQuery
+	timestamp     string
+}
+
+// GetFormat returns int that represents XML or JSON
+func (ch *CombinedQueryHandle) GetFormat() int {
+	return ch.Format
+}
+
+func (ch *CombinedQueryHandle) resetBuffer() {
+	if ch.Buffer == nil {
+		ch.Buffer = new(bytes.Buffer)
+	}
+	ch.Reset()
+}
+
+// Deserialize returns CombinedQuery struct that represents XML or JSON
+func (ch *CombinedQueryHandle) Deserialize(bytes []byte) {
+	ch.resetBuffer()
+	ch.Write(bytes)
+	ch.CombinedQuery = CombinedQuery{}
+	if ch.GetFormat() != handle.JSON {
+		xml.Unmarshal(bytes, &ch.CombinedQuery)
+		return
+	}
+	combined := combinedQueryJSON{}
+	if err := json.Unmarshal(bytes, &combined); err != nil {
+		return
+	}
+	search := combined.Search
+	if len(search.StructuredQuery) > 0 {
+		wrapped, _ := json.Marshal(map[string]json.RawMessage{"query": search.StructuredQuery})
+		if unwrapped, err := unwrapJSON(wrapped, mapperFunction); err == nil {
+			if query, ok := unwrapped.(Query); ok {
+				ch.CombinedQuery.StructuredQuery = query
+			}
+		}
+	}
+	if len(search.QText) > 0 {
+		var qtext string
+		if json.Unmarshal(search.QText, &qtext) == nil {
+			ch.CombinedQuery.QText = []string{qtext}
+		} else {
+			json.Unmarshal(search.QText, &ch.CombinedQuery.QText)
+		}
+	}
+	ch.CombinedQuery.SPARQL = search.SPARQL
+	ch.CombinedQuery.CTSQuery = search.CTSQuery
+	ch.CombinedQuery.Options = search.Options
+}
+
+// Deserialized returns *CombinedQuery as interface{}
+func (ch *CombinedQueryHandle) Deserialized() interface{} {
+	return &ch.CombinedQuery
+}
+
+// Serialize returns []byte of XML or JSON that represents the CombinedQuery
+// struct. Empty parts of the query are left out.
+func (ch *CombinedQueryHandle) Serialize(combinedQuery interface{}) {
+	ch.CombinedQuery = combinedQuery.(CombinedQuery)
+	ch.resetBuffer()
+	combined := ch.CombinedQuery
+	if ch.GetFormat() != handle.JSON {
+		shadow := combinedQueryXML{QText: combined.QText, SPARQL: combined.SPARQL, CTSQuery: combined.CTSQuery, Options: combined.Options}
+		if len(combined.StructuredQuery.Queries) > 0 {
+			shadow.StructuredQuery = &combined.StructuredQuery
+		}
+		xml.NewEncoder(ch).Encode(&shadow)
+		return
+	}
+	shadow := combinedQueryJSON{}
+	if len(combined.StructuredQuery.Queries) > 0 {
+		wrapped, _ := wrapJSON(combined.StructuredQuery)
+		var query map[string]json.RawMessage
+		json.Unmarshal(wrapped, &query)
+		shadow.Search.StructuredQuery = query["query"]
+	}
+	if len(combined.QText) > 0 {
+		shadow.Search.QText, _ = json.Marshal(combined.QText)
+	}
+	shadow.Search.SPARQL = combined.SPARQL
+	shadow.Search.CTSQuery = combined.CTSQuery
+	shadow.Search.Options = combined.Options
+	json.NewEncoder(ch).Encode(&shadow)
+}
+
+// Get returns *CombinedQuery
+func (ch *CombinedQueryHandle) Get() *CombinedQuery {
+	return &ch.CombinedQuery
+}
+
+// Serialized returns string of XML or JSON
+func (ch *CombinedQueryHandle) Serialized() string {
+	ch.Serialize(ch.CombinedQuery)
+	return ch.String()
+}
+
+// SetTimestamp sets the timestamp
+func (ch *CombinedQueryHandle) SetTimestamp(timestamp string) {
+	ch.timestamp = timestamp
+}
+
+// Timestamp retieves a timestamp
+func (ch *CombinedQueryHandle) Timestamp() string {
+	return ch.timestamp
+}
+
+// AcceptResponse handles an *http.Response
+func (ch *CombinedQueryHandle) AcceptResponse(resp *http.Response) error {
+	return handle.CommonHandleAcceptResponse(ch, resp)
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+func TestCombinedQueryXML(t *testing.T) {
+	combined := CombinedQuery{
+		StructuredQuery: Query{Queries: []any{&TermQuery{Terms: []string{"data"}}}},
+		QText:           []string{"star"},
+		CTSQuery:        &RawQuery{Content: `<cts:word-query xmlns:cts="http://marklogic.com/cts"><cts:text>trek</cts:text></cts:word-query>`},
+		Options:         &Options{ReturnFacets: Bool(false)},
+	}
+	ch := CombinedQueryHandle{Format: handle.XML}
+	ch.Serialize(combined)
+	want := `<search xmlns="http://marklogic.com/appservices/search">` +
+		`<query xmlns="http://marklogic.com/appservices/search"><term-query xmlns="http://marklogic.com/appservices/search"><text xmlns="http://marklogic.com/appservices/search">data</text></term-query></query>` +
+		`<qtext xmlns="http://marklogic.com/appservices/search">star</qtext>` +
+		`<word-query xmlns="http://marklogic.com/cts"><text xmlns="http://marklogic.com/cts">trek</text></word-query>` +
+		`<options xmlns="http://marklogic.com/appservices/search"><return-facets xmlns="http://marklogic.com/appservices/search">false</return-facets></options>` +
+		`</search>`
+	if result := ch.Serialized(); result != want {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", result, want)
+	}
+	deserialized := CombinedQueryHandle{Format: handle.XML}
+	deserialized.Deserialize([]byte(want))
+	got := deserialized.Get()
+	wantCTS := `<word-query xmlns="http://marklogic.com/cts"><text xmlns="http://marklogic.com/cts">trek</text></word-query>`
+	if got.CTSQuery == nil || got.CTSQuery.Content != wantCTS || !reflect.DeepEqual(got.QText, combined.QText) || got.Options == nil || *got.Options.ReturnFacets {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", spew.Sdump(got), spew.Sdump(combined))
+	}
+	if reserialized := deserialized.Serialized(); reserialized != want {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", reserialized, want)
+	}
+}
+
+func TestCombinedQueryJSON(t *testing.T) {
+	combined := CombinedQuery{
+		StructuredQuery: Query{Queries: []any{TermQuery{Terms: []string{"data"}}}},
+		QText:           []string{"star"},
+		CTSQuery:        &RawQuery{Content: `{"wordQuery":{"text":["trek"]}}`},
+		Options:         &Options{PageLength: 5},
+	}
+	ch := CombinedQueryHandle{Format: handle.JSON}
+	ch.Serialize(combined)
+	want := `{"search":{"query":{"queries":[{"term-query":{"text":["data"]}}]},"qtext":["star"],"ctsquery":{"wordQuery":{"text":["trek"]}},"options":{"page-length":5}}}`
+	if result := strings.TrimSpace(ch.Serialized()); result != want {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", result, want)
+	}
+	deserialized := CombinedQueryHandle{Format: handle.JSON}
+	deserialized.Deserialize([]byte(`{"search":{"query":{"queries":[{"term-query":{"text":["data"]}}]},"qtext":"star","ctsquery":{"wordQuery":{"text":["trek"]}},"options":{"page-length":5}}}`))
+	got := deserialized.Get()
+	wantQuery := Query{Queries: []any{&TermQuery{Terms: []string{"data"}}}}
+	if !reflect.DeepEqual(got.StructuredQuery, wantQuery) || !reflect.DeepEqual(got.QText, []string{"star"}) || got.CTSQuery.Content != `{"wordQuery":{"text":["trek"]}}` || got.Options.PageLength != 5 {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", spew.Sdump(got), spew.Sdump(combined))
+	}
+}
+
+func TestCombinedQueryJSONThenXML(t *testing.T) {
+	nested := &AndQuery{Queries: []any{&TermQuery{Terms: []string{"data"}}, &OrQuery{Queries: []any{&TermQuery{Terms: []string{"trek"}}}}}}
+	combined := CombinedQuery{StructuredQuery: Query{Queries: []any{nested}}}
+	want := spew.Sdump(combined)
+	(&CombinedQueryHandle{Format: handle.JSON}).Serialize(combined)
+	if result := spew.Sdump(combined); result != want {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", result, want)
+	}
+	ch := CombinedQueryHandle{Format: handle.XML}
+	ch.Serialize(combined)
+	if result := ch.Serialized(); !strings.Contains(result, `<or-query xmlns="http://marklogic.com/appservices/search"><term-query xmlns="http://marklogic.com/appservices/search"><text xmlns="http://marklogic.com/appservices/search">trek</text>`) {
+		t.Errorf("CombinedQuery Results = %+v, Want = %+v", result, "nested or-query")
+	}
+}
+
+func TestExecuteCombinedQuery(t *testing.T) {
+	var body, uri string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		read, _ := io.ReadAll(r.Body)
+		body, uri = string(read), r.URL.RequestURI()
+	}))
+	defer server.Close()
+	ch := &CombinedQueryHandle{Format: handle.XML}
+	ch.Serialize(CombinedQuery{QText: []string{"star"}, Options: &Options{SearchOptions: []string{"unfiltered"}}})
+	NewService(client).Execute(&Request{Query: ch, Start: 1, PageLength: 10}, nil)
+	want := `<search xmlns="http://marklogic.com/appservices/search"><qtext xmlns="http://marklogic.com/appservices/search">star</qtext><options xmlns="http://marklogic.com/appservices/search"><search-option xmlns="http://marklogic.com/appservices/search">unfiltered</search-option></options></search>`
+	if uri != "/search?start=1&pageLength=10" || body != want {
+		t.Errorf("Execute Request = %+v %+v, Want = %+v", uri, body, want)
+	}
+	var unmarshaled CombinedQuery
+	xml.Unmarshal([]byte(body), &unmarshaled)
+	if unmarshaled.Options == nil || unmarshaled.Options.SearchOptions[0] != "unfiltered" {
+		t.Errorf("Unmarshaled Results = %+v", spew.Sdump(unmarshaled))
+	}
+}
@@ -0,0 +1,905 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+const (
+	ctsNamespace       = "http://marklogic.com/cts"
+	xmlSchemaNamespace = "http://www.w3.org/2001/XMLSchema"
+	xsiNamespace       = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Operators of cts range queries
+const (
+	CTSLT = "<"
+	CTSLE = "<="
+	CTSGT = ">"
+	CTSGE = ">="
+	CTSEQ = "="
+	CTSNE = "!="
+)
+
+// CTSQuery is a query in MarkLogic's serialized cts query format, the format
+// of the queries built by the cts functions of server-side code. Element and
+// attribute names are xml.Names and are written in Clark notation, such as
+// {http://example.com}title, in JSON.
+type CTSQuery interface {
+	ctsNode() *ctsNode
+}
+
+// CTSAndQuery represents https://docs.marklogic.com/cts.andQuery
+type CTSAndQuery struct {
+	Queries []CTSQuery
+	Options []string
+}
+
+// CTSOrQuery represents https://docs.marklogic.com/cts.orQuery
+type CTSOrQuery struct {
+	Queries []CTSQuery
+	Options []string
+}
+
+// CTSNotQuery represents https://docs.marklogic.com/cts.notQuery
+type CTSNotQuery struct {
+	Query CTSQuery
+}
+
+// CTSAndNotQuery represents https://docs.marklogic.com/cts.andNotQuery
+type CTSAndNotQuery struct {
+	Positive CTSQuery
+	Negative CTSQuery
+}
+
+// CTSNearQuery represents https://docs.marklogic.com/cts.nearQuery
+type CTSNearQuery struct {
+	Queries  []CTSQuery
+	Distance float64
+	Options  []string
+	Weight   float64
+}
+
+// CTSBoostQuery represents https://docs.marklogic.com/cts.boostQuery
+type CTSBoostQuery struct {
+	Matching CTSQuery
+	Boosting CTSQuery
+}
+
+// CTSWordQuery represents https://docs.marklogic.com/cts.wordQuery
+type CTSWordQuery struct {
+	Text    []string
+	Options []string
+	Weight  float64
+}
+
+// CTSCollectionQuery represents https://docs.marklogic.com/cts.collectionQuery
+type CTSCollectionQuery struct {
+	URIs []string
+}
+
+// CTSDirectoryQuery represents https://docs.marklogic.com/cts.directoryQuery
+type CTSDirectoryQuery struct {
+	URIs     []string
+	Infinite bool
+}
+
+// CTSDocumentQuery represents https://docs.marklogic.com/cts.documentQuery
+type CTSDocumentQuery struct {
+	URIs []string
+}
+
+// CTSPropertiesFragmentQuery represents https://docs.marklogic.com/cts.propertiesFragmentQuery
+type CTSPropertiesFragmentQuery struct {
+	Query CTSQuery
+}
+
+// CTSDocumentFragmentQuery represents https://docs.marklogic.com/cts.documentFragmentQuery
+type CTSDocumentFragmentQuery struct {
+	Query CTSQuery
+}
+
+// CTSLocksFragmentQuery represents https://docs.marklogic.com/cts.locksFragmentQuery
+type CTSLocksFragmentQuery struct {
+	Query CTSQuery
+}
+
+// CTSJSONPropertyScopeQuery represents https://docs.marklogic.com/cts.jsonPropertyScopeQuery
+type CTSJSONPropertyScopeQuery struct {
+	Properties []string
+	Query      CTSQuery
+}
+
+// CTSElementQuery represents https://docs.marklogic.com/cts.elementQuery
+type CTSElementQuery struct {
+	Elements []xml.Name
+	Query    CTSQuery
+}
+
+// CTSJSONPropertyValueQuery represents https://docs.marklogic.com/cts.jsonPropertyValueQuery
+// Values are strings, numbers, booleans or nil.
+type CTSJSONPropertyValueQuery struct {
+	Properties []string
+	Values     []any
+	Options    []string
+	Weight     float64
+}
+
+// CTSJSONPropertyWordQuery represents https://docs.marklogic.com/cts.jsonPropertyWordQuery
+type CTSJSONPropertyWordQuery struct {
+	Properties []string
+	Text       []string
+	Options    []string
+	Weight     float64
+}
+
+// CTSElementValueQuery represents https://docs.marklogic.com/cts.elementValueQuery
+type CTSElementValueQuery struct {
+	Elements []xml.Name
+	Text     []string
+	Options  []string
+	Weight   float64
+}
+
+// CTSElementWordQuery represents https://docs.marklogic.com/cts.elementWordQuery
+type CTSElementWordQuery struct {
+	Elements []xml.Name
+	Text     []string
+	Options  []string
+	Weight   float64
+}
+
+// CTSElementAttributeValueQuery represents https://docs.marklogic.com/cts.elementAttributeValueQuery
+type CTSElementAttributeValueQuery struct {
+	Elements   []xml.Name
+	Attributes []xml.Name
+	Text       []string
+	Options    []string
+	Weight     float64
+}
+
+// CTSElementAttributeWordQuery represents https://docs.marklogic.com/cts.elementAttributeWordQuery
+type CTSElementAttributeWordQuery struct {
+	Elements   []xml.Name
+	Attributes []xml.Name
+	Text       []string
+	Options    []string
+	Weight     float64
+}
+
+// CTSFieldValueQuery represents https://docs.marklogic.com/cts.fieldValueQuery
+type CTSFieldValueQuery struct {
+	Fields  []string
+	Text    []string
+	Options []string
+	Weight  float64
+}
+
+// CTSFieldWordQuery represents https://docs.marklogic.com/cts.fieldWordQuery
+type CTSFieldWordQuery struct {
+	Fields  []string
+	Text    []string
+	Options []string
+	Weight  float64
+}
+
+// CTSJSONPropertyRangeQuery represents https://docs.marklogic.com/cts.jsonPropertyRangeQuery
+// Operator is one of the CTS operator constants. ValueType, such as xs:int,
+// types the values in XML.
+type CTSJSONPropertyRangeQuery struct {
+	Properties []string
+	Operator   string
+	Values     []any
+	ValueType  string
+	Options    []string
+	Weight     float64
+}
+
+// CTSElementRangeQuery represents https://docs.marklogic.com/cts.elementRangeQuery
+type CTSElementRangeQuery struct {
+	Elements  []xml.Name
+	Operator  string
+	Values    []any
+	ValueType string
+	Options   []string
+	Weight    float64
+}
+
+// CTSElementAttributeRangeQuery represents https://docs.marklogic.com/cts.elementAttributeRangeQuery
+type CTSElementAttributeRangeQuery struct {
+	Elements   []xml.Name
+	Attributes []xml.Name
+	Operator   string
+	Values     []any
+	ValueType  string
+	Options    []string
+	Weight     float64
+}
+
+// CTSFieldRangeQuery represents https://docs.marklogic.com/cts.fieldRangeQuery
+type CTSFieldRangeQuery struct {
+	Fields    []string
+	Operator  string
+	Values    []any
+	ValueType string
+	Options   []string
+	Weight    float64
+}
+
+// CTSPathRangeQuery represents https://docs.marklogic.com/cts.pathRangeQuery
+type CTSPathRangeQuery struct {
+	PathExpressions []string
+	Operator        string
+	Values          []any
+	ValueType       string
+	Options         []string
+	Weight          float64
+}
+
+// CTSTrueQuery represents https://docs.marklogic.com/cts.trueQuery
+type CTSTrueQuery struct{}
+
+// CTSFalseQuery represents https://docs.marklogic.com/cts.falseQuery
+type CTSFalseQuery struct{}
+
+// ctsNode is a cts query ready to be serialized
+type ctsNode struct {
+	// name is the JSON name of the query, such as andQuery
+	name       string
+	attributes []ctsProperty
+	properties []ctsProperty
+}
+
+// ctsProperty is a property of a cts query. It is an XML attribute or child
+// elements, one per value; queries without an XML name are written directly
+// as children. single properties are not arrays in JSON.
+type ctsProperty struct {
+	name      string
+	xmlName   string
+	values    []any
+	valueType string
+	single    bool
+	queries   bool
+}
+
+func newCTSNode(name string) *ctsNode {
+	return &ctsNode{name: name}
+}
+
+func (n *ctsNode) attribute(name string, xmlName string, value any, set bool) *ctsNode {
+	if set {
+		n.attributes = append(n.attributes, ctsProperty{name: name, xmlName: xmlName, values: []any{value}, single: true})
+	}
+	return n
+}
+
+func (n *ctsNode) weight(weight float64) *ctsNode {
+	return n.attribute("weight", "weight", weight, weight != 0)
+}
+
+func (n *ctsNode) add(property ctsProperty) *ctsNode {
+	if len(property.values) > 0 {
+		n.properties = append(n.properties, property)
+	}
+	return n
+}
+
+func (n *ctsNode) strings(name string, xmlName string, values []string) *ctsNode {
+	property := ctsProperty{name: name, xmlName: xmlName}
+	for _, value := range values {
+		property.values = append(property.values, value)
+	}
+	return n.add(property)
+}
+
+func (n *ctsNode) names(name string, xmlName string, values []xml.Name) *ctsNode {
+	property := ctsProperty{name: name, xmlName: xmlName}
+	for _, value := range values {
+		property.values = append(property.values, value)
+	}
+	return n.add(property)
+}
+
+func (n *ctsNode) values(values []any, valueType string) *ctsNode {
+	return n.add(ctsProperty{name: "value", xmlName: "value", values: values, valueType: valueType})
+}
+
+func (n *ctsNode) options(options []string) *ctsNode {
+	return n.strings("options", "option", options)
+}
+
+func (n *ctsNode) queries(queries []CTSQuery) *ctsNode {
+	property := ctsProperty{name: "queries", queries: true}
+	for _, query := range queries {
+		property.values = append(property.values, query)
+	}
+	if len(queries) == 0 {
+		// an empty and or or query is still written in JSON
+		property.values = []any{}
+	}
+	n.properties = append(n.properties, property)
+	return n
+}
+
+func (n *ctsNode) query(name string, xmlName string, query CTSQuery) *ctsNode {
+	n.properties = append(n.properties, ctsProperty{name: name, xmlName: xmlName, values: []any{query}, single: true, queries: true})
+	return n
+}
+
+func (q *CTSAndQuery) ctsNode() *ctsNode {
+	return newCTSNode("andQuery").queries(q.Queries).options(q.Options)
+}
+
+func (q *CTSOrQuery) ctsNode() *ctsNode {
+	return newCTSNode("orQuery").queries(q.Queries).options(q.Options)
+}
+
+func (q *CTSNotQuery) ctsNode() *ctsNode {
+	return newCTSNode("notQuery").query("query", "", q.Query)
+}
+
+func (q *CTSAndNotQuery) ctsNode() *ctsNode {
+	return newCTSNode("andNotQuery").query("positiveQuery", "positive", q.Positive).query("negativeQuery", "negative", q.Negative)
+}
+
+func (q *CTSNearQuery) ctsNode() *ctsNode {
+	return newCTSNode("nearQuery").attribute("distance", "distance", q.Distance, q.Distance != 0).weight(q.Weight).
+		queries(q.Queries).options(q.Options)
+}
+
+func (q *CTSBoostQuery) ctsNode() *ctsNode {
+	return newCTSNode("boostQuery").query("matchingQuery", "matching-query", q.Matching).query("boostingQuery", "boosting-query", q.Boosting)
+}
+
+func (q *CTSWordQuery) ctsNode() *ctsNode {
+	return newCTSNode("wordQuery").weight(q.Weight).strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSCollectionQuery) ctsNode() *ctsNode {
+	return newCTSNode("collectionQuery").strings("uris", "uri", q.URIs)
+}
+
+func (q *CTSDirectoryQuery) ctsNode() *ctsNode {
+	depth := "1"
+	if q.Infinite {
+		depth = "infinity"
+	}
+	return newCTSNode("directoryQuery").attribute("depth", "depth", depth, true).strings("uris", "uri", q.URIs)
+}
+
+func (q *CTSDocumentQuery) ctsNode() *ctsNode {
+	return newCTSNode("documentQuery").strings("uris", "uri", q.URIs)
+}
+
+func (q *CTSPropertiesFragmentQuery) ctsNode() *ctsNode {
+	return newCTSNode("propertiesFragmentQuery").query("query", "", q.Query)
+}
+
+func (q *CTSDocumentFragmentQuery) ctsNode() *ctsNode {
+	return newCTSNode("documentFragmentQuery").query("query", "", q.Query)
+}
+
+func (q *CTSLocksFragmentQuery) ctsNode() *ctsNode {
+	return newCTSNode("locksFragmentQuery").query("query", "", q.Query)
+}
+
+func (q *CTSJSONPropertyScopeQuery) ctsNode() *ctsNode {
+	return newCTSNode("jsonPropertyScopeQuery").strings("property", "property", q.Properties).query("query", "", q.Query)
+}
+
+func (q *CTSElementQuery) ctsNode() *ctsNode {
+	return newCTSNode("elementQuery").names("element", "element", q.Elements).query("query", "", q.Query)
+}
+
+func (q *CTSJSONPropertyValueQuery) ctsNode() *ctsNode {
+	return newCTSNode("jsonPropertyValueQuery").weight(q.Weight).strings("property", "property", q.Properties).
+		values(q.Values, "").options(q.Options)
+}
+
+func (q *CTSJSONPropertyWordQuery) ctsNode() *ctsNode {
+	return newCTSNode("jsonPropertyWordQuery").weight(q.Weight).strings("property", "property", q.Properties).
+		strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSElementValueQuery) ctsNode() *ctsNode {
+	return newCTSNode("elementValueQuery").weight(q.Weight).names("element", "element", q.Elements).
+		strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSElementWordQuery) ctsNode() *ctsNode {
+	return newCTSNode("elementWordQuery").weight(q.Weight).names("element", "element", q.Elements).
+		strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSElementAttributeValueQuery) ctsNode() *ctsNode {
+	return newCTSNode("elementAttributeValueQuery").weight(q.Weight).names("element", "element", q.Elements).
+		names("attribute", "attribute", q.Attributes).strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSElementAttributeWordQuery) ctsNode() *ctsNode {
+	return newCTSNode("elementAttributeWordQuery").weight(q.Weight).names("element", "element", q.Elements).
+		names("attribute", "attribute", q.Attributes).strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSFieldValueQuery) ctsNode() *ctsNode {
+	return newCTSNode("fieldValueQuery").weight(q.Weight).strings("field", "field", q.Fields).
+		strings("text", "text", q.Text).options(q.Options)
+}
+
+func (q *CTSFieldWordQuery) ctsNode() *ctsNode {
+	return newCTSNode("fieldWordQuery").weight(q.Weight).strings("field", "field", q.Fields).
+		strings("text", "text", q.Text).options(q.Options)
+}
+
+func rangeNode(name string, operator string, weight float64) *ctsNode {
+	return newCTSNode(name).attribute("operator", "operator", operator, true).weight(weight)
+}
+
+func (q *CTSJSONPropertyRangeQuery) ctsNode() *ctsNode {
+	return rangeNode("jsonPropertyRangeQuery", q.Operator, q.Weight).strings("property", "property", q.Properties).
+		values(q.Values, q.ValueType).options(q.Options)
+}
+
+func (q *CTSElementRangeQuery) ctsNode() *ctsNode {
+	return rangeNode("elementRangeQuery", q.Operator, q.Weight).names("element", "element", q.Elements).
+		values(q.Values, q.ValueType).options(q.Options)
+}
+
+func (q *CTSElementAttributeRangeQuery) ctsNode() *ctsNode {
+	return rangeNode("elementAttributeRangeQuery", q.Operator, q.Weight).names("element", "element", q.Elements).
+		names("attribute", "attribute", q.Attributes).values(q.Values, q.ValueType).options(q.Options)
+}
+
+func (q *CTSFieldRangeQuery) ctsNode() *ctsNode {
+	return rangeNode("fieldRangeQuery", q.Operator, q.Weight).strings("field", "field", q.Fields).
+		values(q.Values, q.ValueType).options(q.Options)
+}
+
+func (q *CTSPathRangeQuery) ctsNode() *ctsNode {
+	return rangeNode("pathRangeQuery", q.Operator, q.Weight).strings("pathExpression", "path-expression", q.PathExpressions).
+		values(q.Values, q.ValueType).options(q.Options)
+}
+
+func (q *CTSTrueQuery) ctsNode() *ctsNode {
+	return newCTSNode("trueQuery")
+}
+
+func (q *CTSFalseQuery) ctsNode() *ctsNode {
+	return newCTSNode("falseQuery")
+}
+
+// ctsNodeOf returns the node of query, or an error when query is nil
+func ctsNodeOf(query CTSQuery) (*ctsNode, error) {
+	if query == nil || reflect.ValueOf(query).IsNil() {
+		return nil, errors.New("cts query is nil")
+	}
+	return query.ctsNode(), nil
+}
+
+// SerializeCTSQuery returns query in the XML or JSON serialized cts query
+// format
+func SerializeCTSQuery(query CTSQuery, format int) ([]byte, error) {
+	node, err := ctsNodeOf(query)
+	if err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	if format == handle.JSON {
+		err = node.writeJSON(buffer)
+		return buffer.Bytes(), err
+	}
+	encoder := xml.NewEncoder(buffer)
+	if err = node.encodeXML(encoder); err == nil {
+		err = encoder.Flush()
+	}
+	return buffer.Bytes(), err
+}
+
+// NewCTSQueryHandle returns a handle sending query as the query of a search,
+// suggest or values request or of a QueryBatcher. JSON queries are wrapped in
+// a ctsquery property.
+func NewCTSQueryHandle(query CTSQuery, format int) (*handle.RawHandle, error) {
+	serialized, err := SerializeCTSQuery(query, format)
+	if err != nil {
+		return nil, err
+	}
+	if format == handle.JSON {
+		serialized = append(append([]byte(`{"ctsquery":`), serialized...), '}')
+	}
+	queryHandle := &handle.RawHandle{Format: format}
+	queryHandle.Serialize(serialized)
+	return queryHandle, nil
+}
+
+// NewRawCTSQuery returns query as the CTSQuery of a CombinedQuery serialized
+// in format
+func NewRawCTSQuery(query CTSQuery, format int) (*RawQuery, error) {
+	serialized, err := SerializeCTSQuery(query, format)
+	if err != nil {
+		return nil, err
+	}
+	return &RawQuery{Content: string(serialized)}, nil
+}
+
+func (n *ctsNode) writeJSON(buffer *bytes.Buffer) error {
+	name, _ := marshalCTSJSON(n.name)
+	buffer.WriteByte('{')
+	buffer.Write(name)
+	buffer.WriteString(":{")
+	for i, property := range append(append([]ctsProperty{}, n.attributes...), n.properties...) {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, _ := marshalCTSJSON(property.name)
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		if !property.single {
+			buffer.WriteByte('[')
+		}
+		for j, value := range property.values {
+			if j > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeCTSJSONValue(buffer, property, value); err != nil {
+				return err
+			}
+		}
+		if !property.single {
+			buffer.WriteByte(']')
+		}
+	}
+	buffer.WriteString("}}")
+	return nil
+}
+
+func writeCTSJSONValue(buffer *bytes.Buffer, property ctsProperty, value any) error {
+	if property.queries {
+		query, _ := value.(CTSQuery)
+		node, err := ctsNodeOf(query)
+		if err != nil {
+			return err
+		}
+		return node.writeJSON(buffer)
+	}
+	if name, ok := value.(xml.Name); ok {
+		value = clarkName(name)
+	}
+	serialized, err := marshalCTSJSON(value)
+	if err != nil {
+		return err
+	}
+	buffer.Write(serialized)
+	return nil
+}
+
+// marshalCTSJSON marshals a value without escaping the < and > of range
+// operators
+func marshalCTSJSON(value any) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// clarkName returns name in Clark notation, {namespace}local
+func clarkName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+func (n *ctsNode) encodeXML(e *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Space: ctsNamespace, Local: hyphenate(n.name)}}
+	for _, attribute := range n.attributes {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attribute.xmlName}, Value: fmt.Sprint(attribute.values[0])})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, property := range n.properties {
+		for _, value := range property.values {
+			if err := encodeCTSXMLValue(e, property, value); err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeCTSXMLValue(e *xml.Encoder, property ctsProperty, value any) error {
+	query, _ := value.(CTSQuery)
+	if property.queries && property.xmlName == "" {
+		node, err := ctsNodeOf(query)
+		if err != nil {
+			return err
+		}
+		return node.encodeXML(e)
+	}
+	start := xml.StartElement{Name: xml.Name{Space: ctsNamespace, Local: property.xmlName}}
+	var text string
+	switch typed := value.(type) {
+	case xml.Name:
+		text = typed.Local
+		if typed.Space != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:_1"}, Value: typed.Space})
+			text = "_1:" + typed.Local
+		}
+	case nil:
+	default:
+		text = fmt.Sprint(value)
+	}
+	if property.valueType != "" {
+		start.Attr = append(start.Attr,
+			xml.Attr{Name: xml.Name{Local: "xmlns:xs"}, Value: xmlSchemaNamespace},
+			xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+			xml.Attr{Name: xml.Name{Local: "xsi:type"}, Value: property.valueType})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if property.queries {
+		node, err := ctsNodeOf(query)
+		if err != nil {
+			return err
+		}
+		if err := node.encodeXML(e); err != nil {
+			return err
+		}
+	} else if err := e.EncodeToken(xml.CharData(text)); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// hyphenate converts the JSON name of a cts query, such as andQuery, to its
+// XML name, and-query
+func hyphenate(name string) string {
+	hyphenated := make([]byte, 0, len(name)+4)
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c >= 'A' && c <= 'Z' {
+			hyphenated = append(hyphenated, '-', c+'a'-'A')
+		} else {
+			hyphenated = append(hyphenated, c)
+		}
+	}
+	return string(hyphenated)
+}
+
+// ctsOperators maps the range operators of structured queries to those of
+// cts queries
+var ctsOperators = map[string]string{
+	RangeLT: CTSLT,
+	RangeLE: CTSLE,
+	RangeGT: CTSGT,
+	RangeGE: CTSGE,
+	RangeEQ: CTSEQ,
+	RangeNE: CTSNE,
+}
+
+// ToCTSQuery converts a structured query, a Query or one of the query types
+// of this package, to a cts query. Queries without a cts equivalent, such as
+// constraint queries, which depend on query options, return an error.
+func ToCTSQuery(query any) (CTSQuery, error) {
+	value := reflect.ValueOf(query)
+	if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return nil, errors.New("query is nil")
+	}
+	if value.Kind() == reflect.Struct {
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		query = pointer.Interface()
+	}
+	switch q := query.(type) {
+	case *Query:
+		return toCTSQuery(q.Queries)
+	case *AndQuery:
+		queries, err := toCTSQueries(q.Queries)
+		if err != nil {
+			return nil, err
+		}
+		var options []string
+		if q.Ordered {
+			options = []string{"ordered"}
+		}
+		return &CTSAndQuery{Queries: queries, Options: options}, nil
+	case *OrQuery:
+		queries, err := toCTSQueries(q.Queries)
+		return &CTSOrQuery{Queries: queries}, err
+	case *NotQuery:
+		negated, err := requiredCTSQuery("not-query", q.Queries)
+		if err != nil {
+			return nil, err
+		}
+		return &CTSNotQuery{Query: negated}, nil
+	case *AndNotQuery:
+		positive, err := requiredCTSQuery("and-not-query positive-query", q.PositiveQuery.Queries)
+		if err != nil {
+			return nil, err
+		}
+		negative, err := requiredCTSQuery("and-not-query negative-query", q.NegativeQuery.Queries)
+		if err != nil {
+			return nil, err
+		}
+		return &CTSAndNotQuery{Positive: positive, Negative: negative}, nil
+	case *NearQuery:
+		queries, err := toCTSQueries(q.Queries)
+		if err != nil {
+			return nil, err
+		}
+		near := &CTSNearQuery{Queries: queries, Distance: float64(q.Distance), Weight: q.DistanceWeight}
+		if q.Ordered {
+			near.Options = []string{"ordered"}
+		}
+		return near, nil
+	case *BoostQuery:
+		matching, err := toCTSQuery(q.MatchingQuery.Queries)
+		if err != nil {
+			return nil, err
+		}
+		boosting, err := toCTSQuery(q.BoostingQuery.Queries)
+		return &CTSBoostQuery{Matching: matching, Boosting: boosting}, err
+	case *TermQuery:
+		return &CTSWordQuery{Text: q.Terms, Weight: q.Weight}, nil
+	case *CollectionQuery:
+		return &CTSCollectionQuery{URIs: q.URIs}, nil
+	case *DirectoryQuery:
+		return &CTSDirectoryQuery{URIs: q.URIs, Infinite: q.Infinite}, nil
+	case *DocumentQuery:
+		return &CTSDocumentQuery{URIs: q.URIs}, nil
+	case *PropertiesQuery:
+		inner, err := toCTSQuery(q.Queries)
+		return &CTSPropertiesFragmentQuery{Query: inner}, err
+	case *DocumentFragmentQuery:
+		inner, err := toCTSQuery(q.Queries)
+		return &CTSDocumentFragmentQuery{Query: inner}, err
+	case *LocksQuery:
+		inner, err := toCTSQuery(q.Queries)
+		return &CTSLocksFragmentQuery{Query: inner}, err
+	case *ContainerQuery:
+		inner, err := toCTSQuery(q.Queries)
+		if err != nil {
+			return nil, err
+		}
+		var container CTSQuery
+		switch {
+		case q.JSONKey != "":
+			container = &CTSJSONPropertyScopeQuery{Properties: []string{q.JSONKey}, Query: inner}
+		case q.Element.Local != "":
+			container = &CTSElementQuery{Elements: []xml.Name{ctsName(q.Element.Namespace, q.Element.Local)}, Query: inner}
+		default:
+			return nil, errors.New("container-query has no element or JSON property")
+		}
+		return inFragmentScope(container, q.FragmentScope), nil
+	case *ValueQuery:
+		value, err := termCTSQuery("value-query", q.Element, q.Attribute, q.JSONKey, q.Field, q.Text, q.TermOptions, q.Weight)
+		return inFragmentScope(value, q.FragmentScope), err
+	case *WordQuery:
+		word, err := termCTSQuery("word-query", q.Element, q.Attribute, q.JSONKey, q.Field, q.Text, q.TermOptions, q.Weight)
+		return inFragmentScope(word, q.FragmentScope), err
+	case *RangeQuery:
+		rangeQuery, err := rangeCTSQuery(q)
+		return inFragmentScope(rangeQuery, q.FragmentScope), err
+	case *TrueQuery:
+		return &CTSTrueQuery{}, nil
+	case *FalseQuery:
+		return &CTSFalseQuery{}, nil
+	}
+	return nil, fmt.Errorf("no cts query for %T", query)
+}
+
+// toCTSQueries converts each of queries
+func toCTSQueries(queries []any) ([]CTSQuery, error) {
+	converted := make([]CTSQuery, 0, len(queries))
+	for _, query := range queries {
+		ctsQuery, err := ToCTSQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, ctsQuery)
+	}
+	return converted, nil
+}
+
+// toCTSQuery converts the child queries of a query, which are implicitly
+// ANDed, to a single cts query
+func toCTSQuery(queries []any) (CTSQuery, error) {
+	converted, err := toCTSQueries(queries)
+	if err != nil {
+		return nil, err
+	}
+	if len(converted) == 1 {
+		return converted[0], nil
+	}
+	return &CTSAndQuery{Queries: converted}, nil
+}
+
+// requiredCTSQuery converts the child queries of a query that needs at least one
+func requiredCTSQuery(name string, queries []any) (CTSQuery, error) {
+	if len(queries) == 0 {
+		return nil, errors.New(name + " has no query")
+	}
+	return toCTSQuery(queries)
+}
+
+func ctsName(namespace string, local string) xml.Name {
+	return xml.Name{Space: namespace, Local: local}
+}
+
+// inFragmentScope restricts query to properties fragments for the properties
+// fragment scope
+func inFragmentScope(query CTSQuery, scope string) CTSQuery {
+	if query != nil && scope == FragmentScopeProperties {
+		return &CTSPropertiesFragmentQuery{Query: query}
+	}
+	return query
+}
+
+// termCTSQuery converts a value or word query
+func termCTSQuery(name string, element QueryElement, attribute QueryAttribute, jsonKey string, field FieldReference, text []string, options []string, weight float64) (CTSQuery, error) {
+	value := name == "value-query"
+	switch {
+	case jsonKey != "" && value:
+		values := make([]any, len(text))
+		for i, t := range text {
+			values[i] = t
+		}
+		return &CTSJSONPropertyValueQuery{Properties: []string{jsonKey}, Values: values, Options: options, Weight: weight}, nil
+	case jsonKey != "":
+		return &CTSJSONPropertyWordQuery{Properties: []string{jsonKey}, Text: text, Options: options, Weight: weight}, nil
+	case field.Name != "" && value:
+		return &CTSFieldValueQuery{Fields: []string{field.Name}, Text: text, Options: options, Weight: weight}, nil
+	case field.Name != "":
+		return &CTSFieldWordQuery{Fields: []string{field.Name}, Text: text, Options: options, Weight: weight}, nil
+	case element.Local == "":
+		return nil, errors.New(name + " has no index")
+	}
+	elements := []xml.Name{ctsName(element.Namespace, element.Local)}
+	switch {
+	case attribute.Local != "" && value:
+		return &CTSElementAttributeValueQuery{Elements: elements, Attributes: []xml.Name{ctsName(attribute.Namespace, attribute.Local)}, Text: text, Options: options, Weight: weight}, nil
+	case attribute.Local != "":
+		return &CTSElementAttributeWordQuery{Elements: elements, Attributes: []xml.Name{ctsName(attribute.Namespace, attribute.Local)}, Text: text, Options: options, Weight: weight}, nil
+	case value:
+		return &CTSElementValueQuery{Elements: elements, Text: text, Options: options, Weight: weight}, nil
+	}
+	return &CTSElementWordQuery{Elements: elements, Text: text, Options: options, Weight: weight}, nil
+}
+
+// rangeCTSQuery converts a range query
+func rangeCTSQuery(q *RangeQuery) (CTSQuery, error) {
+	operator, ok := ctsOperators[q.RangeOperator]
+	if !ok {
+		return nil, errors.New("invalid range operator: " + q.RangeOperator)
+	}
+	values := []any{q.Value}
+	options := q.RangeOptions
+	if q.Collation != "" {
+		options = append(append([]string{}, options...), "collation="+q.Collation)
+	}
+	switch {
+	case q.JSONKey != "":
+		return &CTSJSONPropertyRangeQuery{Properties: []string{q.JSONKey}, Operator: operator, Values: values, ValueType: q.Type, Options: options}, nil
+	case q.Field.Name != "":
+		return &CTSFieldRangeQuery{Fields: []string{q.Field.Name}, Operator: operator, Values: values, ValueType: q.Type, Options: options}, nil
+	case q.PathIndex != "":
+		return &CTSPathRangeQuery{PathExpressions: []string{q.PathIndex}, Operator: operator, Values: values, ValueType: q.Type, Options: options}, nil
+	case q.Element.Local == "":
+		return nil, errors.New("range-query has no index")
+	}
+	elements := []xml.Name{ctsName(q.Element.Namespace, q.Element.Local)}
+	if q.Attribute.Local != "" {
+		return &CTSElementAttributeRangeQuery{Elements: elements, Attributes: []xml.Name{ctsName(q.Attribute.Namespace, q.Attribute.Local)},
+			Operator: operator, Values: values, ValueType: q.Type, Options: options}, nil
+	}
+	return &CTSElementRangeQuery{Elements: elements, Operator: operator, Values: values, ValueType: q.Type, Options: options}, nil
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+var exampleCTSQuery = &CTSAndQuery{
+	Queries: []CTSQuery{
+		&CTSJSONPropertyRangeQuery{Properties: []string{"price"}, Operator: CTSLT, Values: []any{10}, ValueType: "xs:int"},
+		&CTSElementWordQuery{Elements: []xml.Name{{Space: "http://example.com", Local: "title"}}, Text: []string{"star"}, Weight: 2},
+		&CTSNotQuery{Query: &CTSDirectoryQuery{URIs: []string{"/drafts/"}, Infinite: true}},
+	},
+	Options: []string{"ordered"},
+}
+
+func TestCTSQueryJSON(t *testing.T) {
+	want := `{"andQuery":{"queries":[` +
+		`{"jsonPropertyRangeQuery":{"operator":"<","property":["price"],"value":[10]}},` +
+		`{"elementWordQuery":{"weight":2,"element":["{http://example.com}title"],"text":["star"]}},` +
+		`{"notQuery":{"query":{"directoryQuery":{"depth":"infinity","uris":["/drafts/"]}}}}],"options":["ordered"]}}`
+	serialized, err := SerializeCTSQuery(exampleCTSQuery, handle.JSON)
+	if err != nil || string(serialized) != want {
+		t.Errorf("CTSQuery Results = %s %v, Want = %+v", serialized, err, want)
+	}
+	queryHandle, err := NewCTSQueryHandle(exampleCTSQuery, handle.JSON)
+	if err != nil || queryHandle.Serialized() != `{"ctsquery":`+want+`}` {
+		t.Errorf("CTSQuery Handle Results = %+v %v", queryHandle, err)
+	}
+}
+
+func TestCTSQueryXML(t *testing.T) {
+	want := `<and-query xmlns="http://marklogic.com/cts">` +
+		`<json-property-range-query xmlns="http://marklogic.com/cts" operator="&lt;"><property xmlns="http://marklogic.com/cts">price</property>` +
+		`<value xmlns="http://marklogic.com/cts" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="xs:int">10</value></json-property-range-query>` +
+		`<element-word-query xmlns="http://marklogic.com/cts" weight="2"><element xmlns="http://marklogic.com/cts" xmlns:_1="http://example.com">_1:title</element><text xmlns="http://marklogic.com/cts">star</text></element-word-query>` +
+		`<not-query xmlns="http://marklogic.com/cts"><directory-query xmlns="http://marklogic.com/cts" depth="infinity"><uri xmlns="http://marklogic.com/cts">/drafts/</uri></directory-query></not-query>` +
+		`<option xmlns="http://marklogic.com/cts">ordered</option></and-query>`
+	serialized, err := SerializeCTSQuery(exampleCTSQuery, handle.XML)
+	if err != nil || string(serialized) != want {
+		t.Errorf("CTSQuery Results = %s %v, Want = %+v", serialized, err, want)
+	}
+	// the XML form is included as is in a combined query
+	raw, err := NewRawCTSQuery(exampleCTSQuery, handle.XML)
+	if err != nil {
+		t.Fatalf("NewRawCTSQuery Error = %v", err)
+	}
+	combined := CombinedQueryHandle{Format: handle.XML}
+	combined.Serialize(CombinedQuery{CTSQuery: raw})
+	// the namespace prefixes used in content stay declared
+	result := combined.Serialized()
+	for _, declared := range []string{`xmlns:_1="http://example.com">_1:title</element>`, `xmlns:xs="http://www.w3.org/2001/XMLSchema"`} {
+		if !strings.Contains(result, declared) {
+			t.Errorf("CombinedQuery Results = %+v, Want to contain = %+v", result, declared)
+		}
+	}
+}
+
+func TestToCTSQuery(t *testing.T) {
+	query, err := And(
+		Range(JSONProperty("price"), RangeGE, "10").Type("xs:decimal"),
+		Container(Element("", "chapter"), Value(Attribute("", "chapter", "", "lang"), "en")),
+		Properties(Term("draft")),
+	).Ordered().Build()
+	if err != nil {
+		t.Fatalf("Build Error = %v", err)
+	}
+	converted, err := ToCTSQuery(query)
+	if err != nil {
+		t.Fatalf("ToCTSQuery Error = %v", err)
+	}
+	want := `{"andQuery":{"queries":[` +
+		`{"jsonPropertyRangeQuery":{"operator":">=","property":["price"],"value":["10"]}},` +
+		`{"elementQuery":{"element":["chapter"],"query":{"elementAttributeValueQuery":{"element":["chapter"],"attribute":["lang"],"text":["en"]}}}},` +
+		`{"propertiesFragmentQuery":{"query":{"wordQuery":{"text":["draft"]}}}}],"options":["ordered"]}}`
+	if serialized, err := SerializeCTSQuery(converted, handle.JSON); err != nil || string(serialized) != want {
+		t.Errorf("CTSQuery Results = %s %v, Want = %+v", serialized, err, want)
+	}
+	if _, err := ToCTSQuery(Query{Queries: []any{&RangeConstraintQuery{ConstraintName: "price"}}}); err == nil {
+		t.Errorf("ToCTSQuery of a constraint query Results = nil, Want = error")
+	}
+	invalid := []struct {
+		query any
+		want  string
+	}{
+		{(*AndQuery)(nil), "query is nil"},
+		{&OrQuery{Queries: []any{(*AndQuery)(nil)}}, "query is nil"},
+		{NotQuery{}, "not-query has no query"},
+		{&AndNotQuery{}, "and-not-query positive-query has no query"},
+	}
+	for _, c := range invalid {
+		if _, err := ToCTSQuery(c.query); err == nil || err.Error() != c.want {
+			t.Errorf("ToCTSQuery Error = %v, Want = %+v", err, c.want)
+		}
+	}
+	if _, err := SerializeCTSQuery(&CTSOrQuery{Queries: []CTSQuery{nil}}, handle.JSON); err == nil {
+		t.Errorf("SerializeCTSQuery of a nil query Results = nil, Want = error")
+	}
+}
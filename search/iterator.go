@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	clients "github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// defaultIteratorPageLength is the page length of a ResultIterator whose
+// request has none
+const defaultIteratorPageLength = 10
+
+// ResultIterator pages through the results of a search request, starting at
+// the Start of the request, 1 when it is not set, and fetching PageLength
+// results at a time
+type ResultIterator struct {
+	client      *clients.Client
+	request     Request
+	maxResults  int64
+	pointInTime bool
+	results     []Result
+	returned    int64
+	total       int64
+	done        bool
+}
+
+// NewResultIterator returns an iterator over the results of request
+func NewResultIterator(c *clients.Client, request *Request) *ResultIterator {
+	it := &ResultIterator{client: c, request: *request}
+	if it.request.Start < 1 {
+		it.request.Start = 1
+	}
+	if it.request.PageLength < 1 {
+		it.request.PageLength = defaultIteratorPageLength
+	}
+	return it
+}
+
+// WithMaxResults stops the iterator after maxResults results
+func (it *ResultIterator) WithMaxResults(maxResults int64) *ResultIterator {
+	it.maxResults = maxResults
+	return it
+}
+
+// WithPointInTime runs every page at the same point in time, the Timestamp
+// of the request or the timestamp of the first page when it has none, so
+// pages stay consistent while documents are written
+func (it *ResultIterator) WithPointInTime() *ResultIterator {
+	it.pointInTime = true
+	return it
+}
+
+// Total is the number of results of the search, known once a page is fetched
+func (it *ResultIterator) Total() int64 {
+	return it.total
+}
+
+// Timestamp is the point in time pages are run at
+func (it *ResultIterator) Timestamp() string {
+	return it.request.Timestamp
+}
+
+// Next returns the next result, fetching a page when needed, or io.EOF when
+// there are no more results
+func (it *ResultIterator) Next(ctx context.Context) (*Result, error) {
+	if it.maxResults > 0 && it.returned >= it.maxResults {
+		return nil, io.EOF
+	}
+	if len(it.results) == 0 {
+		if it.done {
+			return nil, io.EOF
+		}
+		if err := it.fetch(ctx); err != nil {
+			return nil, err
+		}
+		if len(it.results) == 0 {
+			return nil, io.EOF
+		}
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	it.returned++
+	return &result, nil
+}
+
+// All returns the remaining results for use with range. Iteration stops at
+// the first error, which is yielded with a nil result.
+func (it *ResultIterator) All(ctx context.Context) iter.Seq2[*Result, error] {
+	return func(yield func(*Result, error) bool) {
+		for {
+			result, err := it.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(result, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// fetch runs the request for the next page
+func (it *ResultIterator) fetch(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if it.maxResults > 0 && it.maxResults-it.returned < it.request.PageLength {
+		it.request.PageLength = it.maxResults - it.returned
+	}
+	req, err := it.request.queryRequest(it.client, "/search"+it.request.searchParameters(it.client))
+	if err != nil {
+		return err
+	}
+	response := &ResponseHandle{Format: handle.XML}
+	if err = util.Execute(it.client, req.WithContext(ctx), response); err != nil {
+		return err
+	}
+	page := response.Get()
+	it.results, it.total = page.Results, page.Total
+	it.request.Start += int64(len(page.Results))
+	it.done = len(page.Results) < int(it.request.PageLength) || it.request.Start > it.total
+	if it.pointInTime && it.request.Timestamp == "" {
+		it.request.Timestamp = response.Timestamp()
+	}
+	return nil
+}
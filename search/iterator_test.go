@@ -0,0 +1,92 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ryanjdew/go-marklogic-go/test"
+)
+
+// pagingServer serves the results of a search with total results and records
+// the request URIs
+func pagingServer(total int) (*Service, *[]string, func()) {
+	var uris []string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uris = append(uris, r.URL.RequestURI())
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		pageLength, _ := strconv.Atoi(r.URL.Query().Get("pageLength"))
+		w.Header().Set("ML-Effective-Timestamp", "16000000")
+		fmt.Fprintf(w, `<search:response xmlns:search="http://marklogic.com/appservices/search" total="%d" start="%d" page-length="%d">`, total, start, pageLength)
+		for i := start; i < start+pageLength && i <= total; i++ {
+			fmt.Fprintf(w, `<search:result index="%d" uri="/doc%d.json"/>`, i, i)
+		}
+		fmt.Fprint(w, `</search:response>`)
+	}))
+	return NewService(client), &uris, server.Close
+}
+
+func TestResultIterator(t *testing.T) {
+	service, uris, closer := pagingServer(5)
+	defer closer()
+	it := service.ResultIterator(&Request{Text: "whale", PageLength: 2}).WithPointInTime()
+	var results []string
+	for result, err := range it.All(context.Background()) {
+		if err != nil {
+			t.Fatalf("ResultIterator Error = %v", err)
+		}
+		results = append(results, result.URI)
+	}
+	want := []string{"/doc1.json", "/doc2.json", "/doc3.json", "/doc4.json", "/doc5.json"}
+	if !reflect.DeepEqual(results, want) || it.Total() != 5 {
+		t.Errorf("ResultIterator Results = %+v %d, Want = %+v", results, it.Total(), want)
+	}
+	// pages after the first run at its timestamp
+	wantURIs := []string{
+		"/search?q=whale&start=1&pageLength=2",
+		"/search?q=whale&start=3&pageLength=2&timestamp=16000000",
+		"/search?q=whale&start=5&pageLength=2&timestamp=16000000",
+	}
+	if !reflect.DeepEqual(*uris, wantURIs) {
+		t.Errorf("ResultIterator Requests = %+v, Want = %+v", *uris, wantURIs)
+	}
+}
+
+func TestResultIteratorMaxResults(t *testing.T) {
+	service, uris, closer := pagingServer(10)
+	defer closer()
+	it := service.ResultIterator(&Request{PageLength: 2}).WithMaxResults(3)
+	count := 0
+	for {
+		_, err := it.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ResultIterator Error = %v", err)
+		}
+		count++
+	}
+	wantURIs := []string{"/search?start=1&pageLength=2", "/search?start=3&pageLength=1"}
+	if count != 3 || !reflect.DeepEqual(*uris, wantURIs) {
+		t.Errorf("ResultIterator Results = %d %+v, Want = 3 %+v", count, *uris, wantURIs)
+	}
+}
+
+func TestResultIteratorContext(t *testing.T) {
+	service, uris, closer := pagingServer(5)
+	defer closer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var errs []error
+	for _, err := range service.ResultIterator(&Request{}).All(ctx) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] != context.Canceled || len(*uris) != 0 {
+		t.Errorf("ResultIterator Errors = %+v %+v, Want = %v", errs, *uris, context.Canceled)
+	}
+}
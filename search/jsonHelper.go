@@ -26,21 +26,41 @@ func getXMLName(d reflect.Value, label string) (string, bool) {
 func wrapJSONInterface(item interface{}) (map[string]interface{}, error) {
 	reflectValue := reflect.Indirect(reflect.ValueOf(item))
 	if n, ok := getXMLName(reflectValue, "XMLName"); ok {
-		if k := reflectValue.FieldByName("Queries"); k.IsValid() {
-			for i := 0; i < k.Len(); i++ {
-				b, err1 := wrapJSONInterface(k.Index(i).Interface())
-				if err1 != nil {
-					continue
-				}
-				k.Index(i).Set(reflect.ValueOf(b))
+		// the queries are wrapped in a copy so the item is left unchanged
+		copied := reflect.New(reflectValue.Type())
+		copied.Elem().Set(reflectValue)
+		wrapJSONQueries(copied.Elem())
+		// the positive and negative or matching and boosting queries
+		for i := 0; i < copied.Elem().NumField(); i++ {
+			if field := copied.Elem().Field(i); field.Kind() == reflect.Struct {
+				wrapJSONQueries(field)
 			}
-
 		}
-		return map[string]interface{}{n: item}, nil
+		if reflect.ValueOf(item).Kind() == reflect.Pointer {
+			return map[string]interface{}{n: copied.Interface()}, nil
+		}
+		return map[string]interface{}{n: copied.Elem().Interface()}, nil
 	}
 	return nil, errors.New("YOU FAILED")
 }
 
+// wrapJSONQueries replaces the Queries of a struct with a new slice of the
+// wrapped queries
+func wrapJSONQueries(reflectValue reflect.Value) {
+	if k := reflectValue.FieldByName("Queries"); k.IsValid() && k.Kind() == reflect.Slice && !k.IsNil() {
+		queries := reflect.MakeSlice(k.Type(), k.Len(), k.Len())
+		for i := 0; i < k.Len(); i++ {
+			queries.Index(i).Set(k.Index(i))
+			b, err1 := wrapJSONInterface(k.Index(i).Interface())
+			if err1 != nil {
+				continue
+			}
+			queries.Index(i).Set(reflect.ValueOf(b))
+		}
+		k.Set(queries)
+	}
+}
+
 func wrapJSON(i interface{}) ([]byte, error) {
 	wrappedInterface, err := wrapJSONInterface(i)
 	if err != nil {
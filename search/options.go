@@ -0,0 +1,334 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+// Options represents query options, https://docs.marklogic.com/guide/search-dev/appendixa
+// Boolean settings are pointers so that only those that are set are sent and
+// the server defaults apply to the rest.
+type Options struct {
+	XMLName             xml.Name             `xml:"http://marklogic.com/appservices/search options" json:"-"`
+	Constraints         []Constraint         `xml:"http://marklogic.com/appservices/search constraint" json:"constraint,omitempty"`
+	SortOrders          []SortOrder          `xml:"http://marklogic.com/appservices/search sort-order" json:"sort-order,omitempty"`
+	TransformResults    *TransformResults    `xml:"http://marklogic.com/appservices/search transform-results,omitempty" json:"transform-results,omitempty"`
+	ExtractDocumentData *ExtractDocumentData `xml:"http://marklogic.com/appservices/search extract-document-data,omitempty" json:"extract-document-data,omitempty"`
+	Grammar             *Grammar             `xml:"http://marklogic.com/appservices/search grammar,omitempty" json:"grammar,omitempty"`
+	SearchOptions       []string             `xml:"http://marklogic.com/appservices/search search-option" json:"search-option,omitempty"`
+	PageLength          int64                `xml:"http://marklogic.com/appservices/search page-length,omitempty" json:"page-length,omitempty"`
+	QualityWeight       float64              `xml:"http://marklogic.com/appservices/search quality-weight,omitempty" json:"quality-weight,omitempty"`
+	Fragmentation       string               `xml:"http://marklogic.com/appservices/search fragmentation,omitempty" json:"fragmentation,omitempty"`
+	ReturnAggregates    *bool                `xml:"http://marklogic.com/appservices/search return-aggregates,omitempty" json:"return-aggregates,omitempty"`
+	ReturnConstraints   *bool                `xml:"http://marklogic.com/appservices/search return-constraints,omitempty" json:"return-constraints,omitempty"`
+	ReturnFacets        *bool                `xml:"http://marklogic.com/appservices/search return-facets,omitempty" json:"return-facets,omitempty"`
+	ReturnFrequencies   *bool                `xml:"http://marklogic.com/appservices/search return-frequencies,omitempty" json:"return-frequencies,omitempty"`
+	ReturnMetrics       *bool                `xml:"http://marklogic.com/appservices/search return-metrics,omitempty" json:"return-metrics,omitempty"`
+	ReturnPlan          *bool                `xml:"http://marklogic.com/appservices/search return-plan,omitempty" json:"return-plan,omitempty"`
+	ReturnQText         *bool                `xml:"http://marklogic.com/appservices/search return-qtext,omitempty" json:"return-qtext,omitempty"`
+	ReturnQuery         *bool                `xml:"http://marklogic.com/appservices/search return-query,omitempty" json:"return-query,omitempty"`
+	ReturnResults       *bool                `xml:"http://marklogic.com/appservices/search return-results,omitempty" json:"return-results,omitempty"`
+	ReturnSimilar       *bool                `xml:"http://marklogic.com/appservices/search return-similar,omitempty" json:"return-similar,omitempty"`
+	ReturnValues        *bool                `xml:"http://marklogic.com/appservices/search return-values,omitempty" json:"return-values,omitempty"`
+	Debug               *bool                `xml:"http://marklogic.com/appservices/search debug,omitempty" json:"debug,omitempty"`
+}
+
+// Bool returns a pointer to b for the boolean settings of Options
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Constraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_47124
+// Exactly one of its constraint kinds should be set.
+type Constraint struct {
+	Name                string                `xml:"name,attr" json:"name"`
+	Range               *RangeConstraint      `xml:"http://marklogic.com/appservices/search range,omitempty" json:"range,omitempty"`
+	Collection          *CollectionConstraint `xml:"http://marklogic.com/appservices/search collection,omitempty" json:"collection,omitempty"`
+	Value               *ValueConstraint      `xml:"http://marklogic.com/appservices/search value,omitempty" json:"value,omitempty"`
+	Word                *WordConstraint       `xml:"http://marklogic.com/appservices/search word,omitempty" json:"word,omitempty"`
+	GeoElem             *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-elem,omitempty" json:"geo-elem,omitempty"`
+	GeoElemPair         *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-elem-pair,omitempty" json:"geo-elem-pair,omitempty"`
+	GeoAttrPair         *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-attr-pair,omitempty" json:"geo-attr-pair,omitempty"`
+	GeoJSONProperty     *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-json-property,omitempty" json:"geo-json-property,omitempty"`
+	GeoJSONPropertyPair *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-json-property-pair,omitempty" json:"geo-json-property-pair,omitempty"`
+	GeoPath             *GeoConstraint        `xml:"http://marklogic.com/appservices/search geo-path,omitempty" json:"geo-path,omitempty"`
+	Custom              *CustomConstraint     `xml:"http://marklogic.com/appservices/search custom,omitempty" json:"custom,omitempty"`
+}
+
+// IndexName names an element or attribute
+type IndexName struct {
+	NS   string `xml:"ns,attr" json:"ns"`
+	Name string `xml:"name,attr" json:"name"`
+}
+
+// FieldName names a field
+type FieldName struct {
+	Name      string `xml:"name,attr" json:"name"`
+	Collation string `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+}
+
+// PathIndex is the path expression of a path range index
+type PathIndex struct {
+	Text string `xml:",chardata" json:"text"`
+}
+
+// IndexReference identifies the content a constraint or sort order applies
+// to. Only one of its members should be set, with Attribute also needing
+// Element to name the attribute's parent.
+type IndexReference struct {
+	Element      *IndexName `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
+	Attribute    *IndexName `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitempty"`
+	JSONProperty string     `xml:"http://marklogic.com/appservices/search json-property,omitempty" json:"json-property,omitempty"`
+	Field        *FieldName `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitempty"`
+	PathIndex    *PathIndex `xml:"http://marklogic.com/appservices/search path-index,omitempty" json:"path-index,omitempty"`
+}
+
+// RangeConstraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_64645
+type RangeConstraint struct {
+	Type      string `xml:"type,attr" json:"type"`
+	Collation string `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+	Facet     *bool  `xml:"facet,attr,omitempty" json:"facet,omitempty"`
+	IndexReference
+	Buckets         []Bucket         `xml:"http://marklogic.com/appservices/search bucket" json:"bucket,omitempty"`
+	ComputedBuckets []ComputedBucket `xml:"http://marklogic.com/appservices/search computed-bucket" json:"computed-bucket,omitempty"`
+	FacetOptions    []string         `xml:"http://marklogic.com/appservices/search facet-option" json:"facet-option,omitempty"`
+	RangeOptions    []string         `xml:"http://marklogic.com/appservices/search range-option" json:"range-option,omitempty"`
+}
+
+// Bucket is a named range of values of a range constraint; GE is inclusive
+// and LT exclusive
+type Bucket struct {
+	Name  string `xml:"name,attr" json:"name"`
+	GE    string `xml:"ge,attr,omitempty" json:"ge,omitempty"`
+	LT    string `xml:"lt,attr,omitempty" json:"lt,omitempty"`
+	Label string `xml:",chardata" json:"label"`
+}
+
+// ComputedBucket is a bucket of dateTime values relative to an anchor such as
+// now, start-of-day, start-of-month or start-of-year
+type ComputedBucket struct {
+	Name     string `xml:"name,attr" json:"name"`
+	GE       string `xml:"ge,attr,omitempty" json:"ge,omitempty"`
+	LT       string `xml:"lt,attr,omitempty" json:"lt,omitempty"`
+	Anchor   string `xml:"anchor,attr" json:"anchor"`
+	GEAnchor string `xml:"ge-anchor,attr,omitempty" json:"ge-anchor,omitempty"`
+	LTAnchor string `xml:"lt-anchor,attr,omitempty" json:"lt-anchor,omitempty"`
+	Label    string `xml:",chardata" json:"label"`
+}
+
+// CollectionConstraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_31669
+type CollectionConstraint struct {
+	Prefix       string   `xml:"prefix,attr,omitempty" json:"prefix,omitempty"`
+	Facet        *bool    `xml:"facet,attr,omitempty" json:"facet,omitempty"`
+	FacetOptions []string `xml:"http://marklogic.com/appservices/search facet-option" json:"facet-option,omitempty"`
+}
+
+// ValueConstraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_38268
+type ValueConstraint struct {
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	IndexReference
+	TermOptions []string `xml:"http://marklogic.com/appservices/search term-option" json:"term-option,omitempty"`
+	Weight      float64  `xml:"http://marklogic.com/appservices/search weight,omitempty" json:"weight,omitempty"`
+}
+
+// WordConstraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_89278
+type WordConstraint struct {
+	IndexReference
+	TermOptions []string `xml:"http://marklogic.com/appservices/search term-option" json:"term-option,omitempty"`
+	Weight      float64  `xml:"http://marklogic.com/appservices/search weight,omitempty" json:"weight,omitempty"`
+}
+
+// GeoConstraint represents the geospatial constraints,
+// https://docs.marklogic.com/guide/search-dev/appendixa#id_34479
+// Element, Parent, Lat and Lon name elements and attributes, the *Property
+// members JSON properties and PathIndex a geospatial path index.
+type GeoConstraint struct {
+	Parent         *IndexName `xml:"http://marklogic.com/appservices/search parent,omitempty" json:"parent,omitempty"`
+	Element        *IndexName `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
+	Lat            *IndexName `xml:"http://marklogic.com/appservices/search lat,omitempty" json:"lat,omitempty"`
+	Lon            *IndexName `xml:"http://marklogic.com/appservices/search lon,omitempty" json:"lon,omitempty"`
+	ParentProperty string     `xml:"http://marklogic.com/appservices/search parent-property,omitempty" json:"parent-property,omitempty"`
+	JSONProperty   string     `xml:"http://marklogic.com/appservices/search json-property,omitempty" json:"json-property,omitempty"`
+	LatProperty    string     `xml:"http://marklogic.com/appservices/search lat-property,omitempty" json:"lat-property,omitempty"`
+	LonProperty    string     `xml:"http://marklogic.com/appservices/search lon-property,omitempty" json:"lon-property,omitempty"`
+	PathIndex      *PathIndex `xml:"http://marklogic.com/appservices/search path-index,omitempty" json:"path-index,omitempty"`
+	HeatMap        *HeatMap   `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitempty"`
+	GeoOptions     []string   `xml:"http://marklogic.com/appservices/search geo-option" json:"geo-option,omitempty"`
+	FacetOptions   []string   `xml:"http://marklogic.com/appservices/search facet-option" json:"facet-option,omitempty"`
+}
+
+// Function names an XQuery or JavaScript function of a library module
+type Function struct {
+	Apply string `xml:"apply,attr" json:"apply"`
+	NS    string `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	At    string `xml:"at,attr,omitempty" json:"at,omitempty"`
+}
+
+// CustomConstraint represents https://docs.marklogic.com/guide/search-dev/appendixa#id_73768
+type CustomConstraint struct {
+	Facet        *bool     `xml:"facet,attr,omitempty" json:"facet,omitempty"`
+	Parse        *Function `xml:"http://marklogic.com/appservices/search parse,omitempty" json:"parse,omitempty"`
+	StartFacet   *Function `xml:"http://marklogic.com/appservices/search start-facet,omitempty" json:"start-facet,omitempty"`
+	FinishFacet  *Function `xml:"http://marklogic.com/appservices/search finish-facet,omitempty" json:"finish-facet,omitempty"`
+	FacetOptions []string  `xml:"http://marklogic.com/appservices/search facet-option" json:"facet-option,omitempty"`
+}
+
+// Score sorts results by relevance when set on a SortOrder
+type Score struct{}
+
+// SortOrder represents https://docs.marklogic.com/guide/search-dev/appendixa#id_59208
+type SortOrder struct {
+	Direction string `xml:"direction,attr,omitempty" json:"direction,omitempty"`
+	Type      string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Collation string `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+	IndexReference
+	Score *Score `xml:"http://marklogic.com/appservices/search score,omitempty" json:"score,omitempty"`
+}
+
+// TransformResults represents https://docs.marklogic.com/guide/search-dev/appendixa#id_48787
+type TransformResults struct {
+	Apply           string `xml:"apply,attr" json:"apply"`
+	NS              string `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	At              string `xml:"at,attr,omitempty" json:"at,omitempty"`
+	PerMatchTokens  int64  `xml:"http://marklogic.com/appservices/search per-match-tokens,omitempty" json:"per-match-tokens,omitempty"`
+	MaxMatches      int64  `xml:"http://marklogic.com/appservices/search max-matches,omitempty" json:"max-matches,omitempty"`
+	MaxSnippetChars int64  `xml:"http://marklogic.com/appservices/search max-snippet-chars,omitempty" json:"max-snippet-chars,omitempty"`
+}
+
+// ExtractDocumentData represents https://docs.marklogic.com/guide/search-dev/appendixa#id_44222
+// Selected is one of include, include-with-ancestors, exclude or all.
+type ExtractDocumentData struct {
+	Selected     string   `xml:"selected,attr,omitempty" json:"selected,omitempty"`
+	ExtractPaths []string `xml:"http://marklogic.com/appservices/search extract-path" json:"extract-path,omitempty"`
+}
+
+// Grammar represents https://docs.marklogic.com/guide/search-dev/appendixa#id_51983
+type Grammar struct {
+	Quotation string    `xml:"http://marklogic.com/appservices/search quotation,omitempty" json:"quotation,omitempty"`
+	Implicit  *Implicit `xml:"http://marklogic.com/appservices/search implicit,omitempty" json:"implicit,omitempty"`
+	Starters  []Starter `xml:"http://marklogic.com/appservices/search starter" json:"starter,omitempty"`
+	Joiners   []Joiner  `xml:"http://marklogic.com/appservices/search joiner" json:"joiner,omitempty"`
+}
+
+// Implicit holds the serialized cts query that combines terms without a
+// joiner, e.g. <cts:and-query strength="20" xmlns:cts="http://marklogic.com/cts"/>
+type Implicit struct {
+	Query string `xml:",innerxml"`
+}
+
+// MarshalJSON writes the query as a string, as the JSON options format does
+func (i Implicit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.Query)
+}
+
+// UnmarshalJSON reads the query from a string
+func (i *Implicit) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &i.Query)
+}
+
+// Starter is a grammar token that starts a group or a prefix operator
+type Starter struct {
+	Strength  int64  `xml:"strength,attr" json:"strength"`
+	Apply     string `xml:"apply,attr" json:"apply"`
+	Delimiter string `xml:"delimiter,attr,omitempty" json:"delimiter,omitempty"`
+	Element   string `xml:"element,attr,omitempty" json:"element,omitempty"`
+	Options   string `xml:"options,attr,omitempty" json:"options,omitempty"`
+	Label     string `xml:",chardata" json:"label"`
+}
+
+// Joiner is a grammar token that joins terms, such as AND or NEAR
+type Joiner struct {
+	Strength int64  `xml:"strength,attr" json:"strength"`
+	Apply    string `xml:"apply,attr" json:"apply"`
+	Element  string `xml:"element,attr,omitempty" json:"element,omitempty"`
+	Options  string `xml:"options,attr,omitempty" json:"options,omitempty"`
+	Tokenize string `xml:"tokenize,attr,omitempty" json:"tokenize,omitempty"`
+	Compare  string `xml:"compare,attr,omitempty" json:"compare,omitempty"`
+	Consume  int64  `xml:"consume,attr,omitempty" json:"consume,omitempty"`
+	Label    string `xml:",chardata" json:"label"`
+}
+
+// optionsJSON is the JSON representation of Options, wrapped in an options
+// property
+type optionsJSON struct {
+	Options *Options `json:"options"`
+}
+
+// OptionsHandle is a handle that places the results into
+// an Options struct
+type OptionsHandle struct {
+	*bytes.Buffer
+	Format    int
+	Options   Options
+	timestamp string
+}
+
+// GetFormat returns int that represents XML or JSON
+func (oh *OptionsHandle) GetFormat() int {
+	return oh.Format
+}
+
+func (oh *OptionsHandle) resetBuffer() {
+	if oh.Buffer == nil {
+		oh.Buffer = new(bytes.Buffer)
+	}
+	oh.Reset()
+}
+
+// Deserialize returns Options struct that represents XML or JSON
+func (oh *OptionsHandle) Deserialize(bytes []byte) {
+	oh.resetBuffer()
+	oh.Write(bytes)
+	oh.Options = Options{}
+	if oh.GetFormat() == handle.JSON {
+		json.Unmarshal(bytes, &optionsJSON{Options: &oh.Options})
+	} else {
+		xml.Unmarshal(bytes, &oh.Options)
+	}
+}
+
+// Deserialized returns *Options as interface{}
+func (oh *OptionsHandle) Deserialized() interface{} {
+	return &oh.Options
+}
+
+// Serialize returns []byte of XML or JSON that represents the Options struct
+func (oh *OptionsHandle) Serialize(options interface{}) {
+	oh.Options = options.(Options)
+	oh.resetBuffer()
+	if oh.GetFormat() == handle.JSON {
+		json.NewEncoder(oh).Encode(optionsJSON{Options: &oh.Options})
+	} else {
+		xml.NewEncoder(oh).Encode(&oh.Options)
+	}
+}
+
+// Get returns *Options
+func (oh *OptionsHandle) Get() *Options {
+	return &oh.Options
+}
+
+// Serialized returns string of XML or JSON
+func (oh *OptionsHandle) Serialized() string {
+	oh.Serialize(oh.Options)
+	return oh.String()
+}
+
+// SetTimestamp sets the timestamp
+func (oh *OptionsHandle) SetTimestamp(timestamp string) {
+	oh.timestamp = timestamp
+}
+
+// Timestamp retieves a timestamp
+func (oh *OptionsHandle) Timestamp() string {
+	return oh.timestamp
+}
+
+// AcceptResponse handles an *http.Response
+func (oh *OptionsHandle) AcceptResponse(resp *http.Response) error {
+	return handle.CommonHandleAcceptResponse(oh, resp)
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+var exampleOptions = Options{
+	Constraints: []Constraint{
+		{Name: "price", Range: &RangeConstraint{
+			Type:           "xs:decimal",
+			Facet:          Bool(true),
+			IndexReference: IndexReference{JSONProperty: "price"},
+			Buckets:        []Bucket{{Name: "low", LT: "10", Label: "Under 10"}, {Name: "high", GE: "10", Label: "10 and up"}},
+			FacetOptions:   []string{"limit=10"},
+		}},
+		{Name: "updated", Range: &RangeConstraint{
+			Type:            "xs:dateTime",
+			Facet:           Bool(true),
+			IndexReference:  IndexReference{Element: &IndexName{NS: "http://example.com", Name: "updated"}},
+			ComputedBuckets: []ComputedBucket{{Name: "today", GE: "P0D", LT: "P1D", Anchor: "start-of-day", Label: "Today"}},
+		}},
+		{Name: "tag", Collection: &CollectionConstraint{Prefix: "/tags/", Facet: Bool(true)}},
+		{Name: "title", Value: &ValueConstraint{IndexReference: IndexReference{Field: &FieldName{Name: "title"}}, TermOptions: []string{"case-insensitive"}, Weight: 2}},
+		{Name: "body", Word: &WordConstraint{IndexReference: IndexReference{Element: &IndexName{Name: "p"}, Attribute: &IndexName{Name: "lang"}}}},
+		{Name: "location", GeoJSONPropertyPair: &GeoConstraint{ParentProperty: "location", LatProperty: "lat", LonProperty: "lon", GeoOptions: []string{"units=miles"}, HeatMap: &HeatMap{North: 50, East: -60, South: 20, West: -130, Latdivs: 4, Londivs: 4}}},
+		{Name: "near", Custom: &CustomConstraint{Facet: Bool(false), Parse: &Function{Apply: "parse", NS: "http://example.com/near", At: "/near.xqy"}}},
+	},
+	SortOrders: []SortOrder{
+		{Direction: "descending", Score: &Score{}},
+		{Direction: "ascending", Type: "xs:string", IndexReference: IndexReference{PathIndex: &PathIndex{Text: "/book/title"}}},
+	},
+	TransformResults:    &TransformResults{Apply: "snippet", PerMatchTokens: 30, MaxMatches: 4},
+	ExtractDocumentData: &ExtractDocumentData{Selected: "include", ExtractPaths: []string{"/title", "/price"}},
+	Grammar: &Grammar{
+		Quotation: `"`,
+		Implicit:  &Implicit{Query: `<cts:and-query xmlns:cts="http://marklogic.com/cts" strength="20"></cts:and-query>`},
+		Starters:  []Starter{{Strength: 30, Apply: "grouping", Delimiter: ")", Label: "("}},
+		Joiners:   []Joiner{{Strength: 10, Apply: "infix", Element: "cts:or-query", Tokenize: "word", Label: "OR"}},
+	},
+	SearchOptions: []string{"unfiltered"},
+	PageLength:    25,
+	ReturnFacets:  Bool(true),
+	ReturnMetrics: Bool(false),
+}
+
+func TestOptionsRoundTrip(t *testing.T) {
+	for _, format := range []int{handle.XML, handle.JSON} {
+		serializer := OptionsHandle{Format: format}
+		serializer.Serialize(exampleOptions)
+		serialized := serializer.Serialized()
+		deserializer := OptionsHandle{Format: format}
+		deserializer.Deserialize([]byte(serialized))
+		want := exampleOptions
+		if format == handle.XML {
+			want.XMLName = xml.Name{Space: "http://marklogic.com/appservices/search", Local: "options"}
+			deserializer.Get().Constraints[5].GeoJSONPropertyPair.HeatMap.XMLName = xml.Name{}
+		}
+		if !reflect.DeepEqual(deserializer.Get(), &want) {
+			t.Errorf("Options %d Results = %+v, Want = %+v", format, spew.Sdump(deserializer.Get()), spew.Sdump(&want))
+		}
+	}
+}
+
+func TestOptionsJSON(t *testing.T) {
+	oh := OptionsHandle{Format: handle.JSON}
+	oh.Serialize(Options{
+		Constraints:  []Constraint{{Name: "price", Range: &RangeConstraint{Type: "xs:int", IndexReference: IndexReference{JSONProperty: "price"}}}},
+		ReturnFacets: Bool(false),
+	})
+	want := `{"options":{"constraint":[{"name":"price","range":{"type":"xs:int","json-property":"price"}}],"return-facets":false}}`
+	if result := strings.TrimSpace(oh.Serialized()); result != want {
+		t.Errorf("Options Results = %+v, Want = %+v", result, want)
+	}
+}
+
+func TestOptionsDeserializeXML(t *testing.T) {
+	serverOptions := `<search:options xmlns:search="http://marklogic.com/appservices/search">
+  <search:constraint name="decade">
+    <search:range facet="true" type="xs:gYear">
+      <search:bucket lt="1930" ge="1920" name="1920s">1920s</search:bucket>
+      <search:facet-option>limit=10</search:facet-option>
+      <search:attribute ns="" name="year"/>
+      <search:element ns="http://marklogic.com/wikipedia" name="nominee"/>
+    </search:range>
+  </search:constraint>
+  <search:return-results>false</search:return-results>
+</search:options>`
+	oh := OptionsHandle{Format: handle.XML}
+	oh.Deserialize([]byte(serverOptions))
+	constraint := oh.Get().Constraints[0]
+	want := &RangeConstraint{
+		Type:           "xs:gYear",
+		Facet:          Bool(true),
+		IndexReference: IndexReference{Element: &IndexName{NS: "http://marklogic.com/wikipedia", Name: "nominee"}, Attribute: &IndexName{Name: "year"}},
+		Buckets:        []Bucket{{Name: "1920s", GE: "1920", LT: "1930", Label: "1920s"}},
+		FacetOptions:   []string{"limit=10"},
+	}
+	if constraint.Name != "decade" || !reflect.DeepEqual(constraint.Range, want) || oh.Get().ReturnResults == nil || *oh.Get().ReturnResults {
+		t.Errorf("Options Results = %+v, Want = %+v", spew.Sdump(oh.Get()), spew.Sdump(want))
+	}
+}
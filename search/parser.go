@@ -0,0 +1,433 @@
+package search
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultGrammar returns MarkLogic's default search grammar, used by a
+// Parser when the options have no grammar
+func DefaultGrammar() *Grammar {
+	return &Grammar{
+		Quotation: `"`,
+		Implicit:  &Implicit{Query: `<cts:and-query strength="20" xmlns:cts="http://marklogic.com/cts"/>`},
+		Starters: []Starter{
+			{Strength: 30, Apply: "grouping", Delimiter: ")", Label: "("},
+			{Strength: 40, Apply: "prefix", Element: "cts:not-query", Label: "-"},
+			{Strength: 40, Apply: "prefix", Element: "cts:not-query", Label: "NOT"},
+		},
+		Joiners: []Joiner{
+			{Strength: 10, Apply: "infix", Element: "cts:or-query", Tokenize: "word", Label: "OR"},
+			{Strength: 20, Apply: "infix", Element: "cts:and-query", Tokenize: "word", Label: "AND"},
+			{Strength: 30, Apply: "infix", Element: "cts:near-query", Tokenize: "word", Label: "NEAR"},
+			{Strength: 30, Apply: "near2", Element: "cts:near-query", Consume: 2, Label: "NEAR/"},
+			{Strength: 32, Apply: "boost", Element: "cts:boost-query", Tokenize: "word", Label: "BOOST"},
+			{Strength: 35, Apply: "not-in", Element: "cts:not-in-query", Tokenize: "word", Label: "NOT_IN"},
+			{Strength: 50, Apply: "constraint", Label: ":"},
+			{Strength: 50, Apply: "constraint", Compare: RangeLT, Tokenize: "word", Label: "LT"},
+			{Strength: 50, Apply: "constraint", Compare: RangeLE, Tokenize: "word", Label: "LE"},
+			{Strength: 50, Apply: "constraint", Compare: RangeGT, Tokenize: "word", Label: "GT"},
+			{Strength: 50, Apply: "constraint", Compare: RangeGE, Tokenize: "word", Label: "GE"},
+			{Strength: 50, Apply: "constraint", Compare: RangeNE, Tokenize: "word", Label: "NE"},
+		},
+	}
+}
+
+// Parser parses string queries, such as title:"moby dick" AND year GT 1850,
+// into structured queries using the grammar and constraints of query
+// options, so they can be validated and changed before they are sent
+type Parser struct {
+	quotation        string
+	implicit         string
+	implicitStrength int64
+	words            map[string]grammarToken
+	symbols          []grammarToken
+	constraints      map[string]Constraint
+}
+
+// grammarToken is a starter, a joiner or the delimiter of a grouping starter
+type grammarToken struct {
+	label     string
+	starter   *Starter
+	joiner    *Joiner
+	delimiter bool
+}
+
+// NewParser returns a parser for the grammar and constraints of options,
+// using DefaultGrammar when options is nil or has no grammar
+func NewParser(options *Options) *Parser {
+	grammar := DefaultGrammar()
+	p := &Parser{words: map[string]grammarToken{}, constraints: map[string]Constraint{}}
+	if options != nil {
+		if options.Grammar != nil {
+			grammar = options.Grammar
+		}
+		for _, constraint := range options.Constraints {
+			p.constraints[constraint.Name] = constraint
+		}
+	}
+	p.quotation = grammar.Quotation
+	p.implicit, p.implicitStrength = "and-query", 20
+	if grammar.Implicit != nil {
+		p.implicit, p.implicitStrength = implicitQuery(grammar.Implicit.Query)
+	}
+	for i := range grammar.Starters {
+		starter := &grammar.Starters[i]
+		p.addToken(grammarToken{label: starter.Label, starter: starter}, false)
+		if starter.Delimiter != "" {
+			p.addToken(grammarToken{label: starter.Delimiter, delimiter: true}, false)
+		}
+	}
+	for i := range grammar.Joiners {
+		joiner := &grammar.Joiners[i]
+		p.addToken(grammarToken{label: joiner.Label, joiner: joiner}, joiner.Tokenize == "word")
+	}
+	// longer symbols are matched first
+	sort.SliceStable(p.symbols, func(i, j int) bool {
+		return len(p.symbols[i].label) > len(p.symbols[j].label)
+	})
+	return p
+}
+
+func (p *Parser) addToken(token grammarToken, word bool) {
+	if token.label == "" {
+		return
+	}
+	if word || isWord(token.label) {
+		p.words[token.label] = token
+	} else {
+		p.symbols = append(p.symbols, token)
+	}
+}
+
+// isWord tells whether a label is made of letters, digits and underscores,
+// and so only matches whole words
+func isWord(label string) bool {
+	return strings.IndexFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) < 0
+}
+
+// implicitQuery returns the local name and strength of the implicit query of
+// a grammar, and-query with a strength of 20 when they are not set
+func implicitQuery(query string) (string, int64) {
+	name, strength := "and-query", int64(20)
+	decoder := xml.NewDecoder(strings.NewReader(query))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return name, strength
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			name = start.Name.Local
+			for _, attribute := range start.Attr {
+				if attribute.Name.Local == "strength" {
+					if parsed, err := strconv.ParseInt(attribute.Value, 10, 64); err == nil {
+						strength = parsed
+					}
+				}
+			}
+			return name, strength
+		}
+	}
+}
+
+// Parse parses text into a structured query. Terms and phrases become term
+// queries and constraint:value pairs the constraint query of their
+// constraint.
+func (p *Parser) Parse(text string) (Query, error) {
+	tokens, err := p.tokenize(text)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(tokens) == 0 {
+		return Query{}, nil
+	}
+	parser := &queryParser{Parser: p, tokens: tokens}
+	query, err := parser.expression(0)
+	if err != nil {
+		return Query{}, err
+	}
+	if token := parser.peek(); token != nil {
+		return Query{}, fmt.Errorf("unexpected %s", token.text)
+	}
+	return Query{Queries: []any{query}}, nil
+}
+
+// queryToken is a term, a phrase or a token of the grammar in a string query
+type queryToken struct {
+	text    string
+	phrase  bool
+	grammar *grammarToken
+}
+
+// tokenize splits text into terms, phrases and grammar tokens. Symbols break
+// terms, except for prefix starters, which only apply at the start of a
+// term.
+func (p *Parser) tokenize(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		if p.quotation != "" && strings.HasPrefix(text[i:], p.quotation) {
+			start := i + len(p.quotation)
+			end := strings.Index(text[start:], p.quotation)
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing %s", p.quotation)
+			}
+			tokens = append(tokens, queryToken{text: text[start : start+end], phrase: true})
+			i = start + end + len(p.quotation)
+			continue
+		}
+		if symbol := p.symbolAt(text[i:], true); symbol != nil {
+			tokens = append(tokens, queryToken{text: symbol.label, grammar: symbol})
+			i += len(symbol.label)
+			continue
+		}
+		end := i
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if unicode.IsSpace(r) || (p.quotation != "" && strings.HasPrefix(text[end:], p.quotation)) || p.symbolAt(text[end:], false) != nil {
+				break
+			}
+			end += size
+		}
+		word := text[i:end]
+		token := queryToken{text: word}
+		if grammar, ok := p.words[word]; ok {
+			token.grammar = &grammar
+		}
+		tokens = append(tokens, token)
+		i = end
+	}
+	return tokens, nil
+}
+
+// symbolAt returns the symbol text starts with. Prefix starters are only
+// matched at the start of a term.
+func (p *Parser) symbolAt(text string, termStart bool) *grammarToken {
+	for i, symbol := range p.symbols {
+		if !termStart && symbol.starter != nil && symbol.starter.Apply == "prefix" {
+			continue
+		}
+		if strings.HasPrefix(text, symbol.label) {
+			return &p.symbols[i]
+		}
+	}
+	return nil
+}
+
+// queryParser holds the state of parsing one string query
+type queryParser struct {
+	*Parser
+	tokens []queryToken
+	pos    int
+}
+
+func (q *queryParser) peek() *queryToken {
+	if q.pos < len(q.tokens) {
+		return &q.tokens[q.pos]
+	}
+	return nil
+}
+
+func (q *queryParser) next() *queryToken {
+	token := q.peek()
+	if token != nil {
+		q.pos++
+	}
+	return token
+}
+
+// expression parses the queries joined by joiners stronger than strength
+func (q *queryParser) expression(strength int64) (any, error) {
+	left, err := q.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := q.peek()
+		if token == nil || (token.grammar != nil && token.grammar.delimiter) {
+			return left, nil
+		}
+		if token.grammar != nil && token.grammar.joiner != nil {
+			joiner := token.grammar.joiner
+			if joiner.Strength <= strength {
+				return left, nil
+			}
+			q.next()
+			if left, err = q.join(joiner, left); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if q.implicitStrength <= strength {
+			return left, nil
+		}
+		right, err := q.expression(q.implicitStrength)
+		if err != nil {
+			return nil, err
+		}
+		left = combineQueries(q.implicit, left, right)
+	}
+}
+
+// operand parses a term, a phrase, a constraint, a group or a prefixed query
+func (q *queryParser) operand() (any, error) {
+	token := q.next()
+	if token == nil {
+		return nil, errors.New("unexpected end of query")
+	}
+	if token.grammar == nil {
+		if next := q.peek(); !token.phrase && next != nil && next.grammar != nil && next.grammar.joiner != nil && next.grammar.joiner.Apply == "constraint" {
+			q.next()
+			return q.constraint(token.text, next.grammar.joiner)
+		}
+		return &TermQuery{Terms: []string{token.text}}, nil
+	}
+	starter := token.grammar.starter
+	if starter == nil {
+		return nil, fmt.Errorf("unexpected %s", token.text)
+	}
+	switch starter.Apply {
+	case "grouping":
+		query, err := q.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := q.next(); closing == nil || closing.grammar == nil || !closing.grammar.delimiter || closing.text != starter.Delimiter {
+			return nil, fmt.Errorf("missing %s", starter.Delimiter)
+		}
+		return query, nil
+	case "prefix":
+		query, err := q.expression(starter.Strength)
+		if err != nil {
+			return nil, err
+		}
+		return &NotQuery{Queries: []any{query}}, nil
+	}
+	return nil, fmt.Errorf("unsupported starter: %s", starter.Apply)
+}
+
+// join applies a joiner to the query on its left and the queries on its
+// right
+func (q *queryParser) join(joiner *Joiner, left any) (any, error) {
+	var distance int64
+	if joiner.Apply == "near2" {
+		token := q.next()
+		if token == nil {
+			return nil, fmt.Errorf("missing distance of %s", joiner.Label)
+		}
+		parsed, err := strconv.ParseInt(token.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid distance: %s", token.text)
+		}
+		distance = parsed
+	}
+	if joiner.Apply == "constraint" {
+		return nil, fmt.Errorf("unexpected %s", joiner.Label)
+	}
+	right, err := q.expression(joiner.Strength)
+	if err != nil {
+		return nil, err
+	}
+	switch joiner.Apply {
+	case "infix":
+		return combineQueries(localName(joiner.Element), left, right), nil
+	case "near2":
+		return &NearQuery{Queries: []any{left, right}, Distance: distance}, nil
+	case "boost":
+		return &BoostQuery{MatchingQuery: MatchingQuery{Queries: []any{left}}, BoostingQuery: BoostingQuery{Queries: []any{right}}}, nil
+	case "not-in":
+		return &NotInQuery{PositiveQuery: PositiveQuery{Queries: []any{left}}, NegativeQuery: NegativeQuery{Queries: []any{right}}}, nil
+	}
+	return nil, fmt.Errorf("unsupported joiner: %s", joiner.Apply)
+}
+
+// localName returns the name of a grammar element without its prefix
+func localName(element string) string {
+	return element[strings.IndexByte(element, ':')+1:]
+}
+
+// combineQueries joins two queries with an and, or or near query, adding
+// right to left when left is already a query of that kind
+func combineQueries(name string, left any, right any) any {
+	switch name {
+	case "or-query":
+		if or, ok := left.(*OrQuery); ok {
+			or.Queries = append(or.Queries, right)
+			return or
+		}
+		return &OrQuery{Queries: []any{left, right}}
+	case "near-query":
+		if near, ok := left.(*NearQuery); ok && near.Distance == 0 {
+			near.Queries = append(near.Queries, right)
+			return near
+		}
+		return &NearQuery{Queries: []any{left, right}}
+	}
+	if and, ok := left.(*AndQuery); ok {
+		and.Queries = append(and.Queries, right)
+		return and
+	}
+	return &AndQuery{Queries: []any{left, right}}
+}
+
+// constraint parses the value of a constraint into the constraint query of
+// its kind
+func (q *queryParser) constraint(name string, joiner *Joiner) (any, error) {
+	constraint, ok := q.constraints[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown constraint: %s", name)
+	}
+	token := q.next()
+	if token == nil || (token.grammar != nil && !token.phrase) {
+		return nil, fmt.Errorf("missing value of constraint %s", name)
+	}
+	value := token.text
+	if joiner.Compare != "" && constraint.Range == nil {
+		return nil, fmt.Errorf("%s requires a range constraint: %s", joiner.Label, name)
+	}
+	switch {
+	case constraint.Range != nil:
+		return &RangeConstraintQuery{ConstraintName: name, Values: []string{value}, RangeOperator: joiner.Compare}, nil
+	case constraint.Value != nil:
+		return &ValueConstraintQuery{ConstraintName: name, Text: []string{value}}, nil
+	case constraint.Word != nil:
+		return &WordConstraintQuery{ConstraintName: name, Text: []string{value}}, nil
+	case constraint.Collection != nil:
+		return &CollectionConstraintQuery{ConstraintName: name, URIs: []string{value}}, nil
+	case constraint.Custom != nil:
+		return &CustomConstraintQuery{ConstraintName: name, Text: []string{value}}, nil
+	case constraint.GeoElem != nil, constraint.GeoElemPair != nil, constraint.GeoAttrPair != nil,
+		constraint.GeoJSONProperty != nil, constraint.GeoJSONPropertyPair != nil, constraint.GeoPath != nil:
+		point, err := parsePoint(value)
+		if err != nil {
+			return nil, err
+		}
+		return &GeospatialConstraintQuery{ConstraintName: name, Points: []*Point{point}}, nil
+	}
+	return nil, fmt.Errorf("constraint %s has no kind", name)
+}
+
+// parsePoint parses a point written as latitude,longitude
+func parsePoint(value string) (*Point, error) {
+	coordinates := strings.Split(value, ",")
+	if len(coordinates) == 2 {
+		latitude, latErr := strconv.ParseFloat(strings.TrimSpace(coordinates[0]), 64)
+		longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(coordinates[1]), 64)
+		if latErr == nil && lonErr == nil {
+			return &Point{Latitude: latitude, Longitude: longitude}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid point: %s", value)
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+var parserOptions = &Options{
+	Constraints: []Constraint{
+		{Name: "title", Word: &WordConstraint{IndexReference: IndexReference{JSONProperty: "title"}}},
+		{Name: "year", Range: &RangeConstraint{Type: "xs:int", IndexReference: IndexReference{JSONProperty: "year"}}},
+		{Name: "tag", Collection: &CollectionConstraint{Prefix: "tag/"}},
+	},
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]any{
+		`title:"moby dick" AND year GT 1850 -draft`: &AndQuery{Queries: []any{
+			&WordConstraintQuery{ConstraintName: "title", Text: []string{"moby dick"}},
+			&RangeConstraintQuery{ConstraintName: "year", Values: []string{"1850"}, RangeOperator: RangeGT},
+			&NotQuery{Queries: []any{&TermQuery{Terms: []string{"draft"}}}},
+		}},
+		`whale OR (sea NEAR/5 ship) tag:classic`: &OrQuery{Queries: []any{
+			&TermQuery{Terms: []string{"whale"}},
+			&AndQuery{Queries: []any{
+				&NearQuery{Queries: []any{&TermQuery{Terms: []string{"sea"}}, &TermQuery{Terms: []string{"ship"}}}, Distance: 5},
+				&CollectionConstraintQuery{ConstraintName: "tag", URIs: []string{"classic"}},
+			}},
+		}},
+		`NOT sea NEAR ship whale NOT_IN "white whale"`: &AndQuery{Queries: []any{
+			// NOT binds tighter than NEAR
+			&NearQuery{Queries: []any{&NotQuery{Queries: []any{&TermQuery{Terms: []string{"sea"}}}}, &TermQuery{Terms: []string{"ship"}}}},
+			&NotInQuery{
+				PositiveQuery: PositiveQuery{Queries: []any{&TermQuery{Terms: []string{"whale"}}}},
+				NegativeQuery: NegativeQuery{Queries: []any{&TermQuery{Terms: []string{"white whale"}}}},
+			},
+		}},
+		`year:1851`: &RangeConstraintQuery{ConstraintName: "year", Values: []string{"1851"}},
+	}
+	parser := NewParser(parserOptions)
+	for text, want := range cases {
+		query, err := parser.Parse(text)
+		if err != nil || !reflect.DeepEqual(query, Query{Queries: []any{want}}) {
+			t.Errorf("Parse(%s) Results = %+v %v, Want = %+v", text, spew.Sdump(query), err, spew.Sdump(want))
+		}
+	}
+}
+
+func TestParseGrammar(t *testing.T) {
+	// a grammar of the options replaces the default one
+	options := &Options{Grammar: &Grammar{
+		Quotation: "'",
+		Implicit:  &Implicit{Query: `<cts:or-query strength="10" xmlns:cts="http://marklogic.com/cts"/>`},
+		Joiners:   []Joiner{{Strength: 20, Apply: "infix", Element: "cts:and-query", Tokenize: "word", Label: "&"}},
+	}}
+	query, err := NewParser(options).Parse(`a 'b c' & d`)
+	want := Query{Queries: []any{&OrQuery{Queries: []any{
+		&TermQuery{Terms: []string{"a"}},
+		&AndQuery{Queries: []any{&TermQuery{Terms: []string{"b c"}}, &TermQuery{Terms: []string{"d"}}}},
+	}}}}
+	if err != nil || !reflect.DeepEqual(query, want) {
+		t.Errorf("Parse Results = %+v %v, Want = %+v", spew.Sdump(query), err, spew.Sdump(want))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		`author:twain`:      "unknown constraint: author",
+		`title LT moby`:     "LT requires a range constraint: title",
+		`(whale OR sea`:     "missing )",
+		`whale)`:            "unexpected )",
+		`whale AND`:         "unexpected end of query",
+		`OR whale`:          "unexpected OR",
+		`"moby dick`:        `missing closing "`,
+		`sea NEAR/far ship`: "invalid distance: far",
+		`year:`:             "missing value of constraint year",
+	}
+	parser := NewParser(parserOptions)
+	for text, want := range cases {
+		if _, err := parser.Parse(text); err == nil || err.Error() != want {
+			t.Errorf("Parse(%s) Error = %v, Want = %+v", text, err, want)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package search
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+
+	clients "github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/util"
+)
+
+const (
+	qbeNamespace = "http://marklogic.com/appservices/querybyexample"
+)
+
+// Operators of QBE range queries
+const (
+	QBELT = "lt"
+	QBELE = "le"
+	QBEGT = "gt"
+	QBEGE = "ge"
+	QBENE = "ne"
+)
+
+var qbeOperators = map[string]bool{QBELT: true, QBELE: true, QBEGT: true, QBEGE: true, QBENE: true}
+
+// QBE is a query by example, a query written as the example of the documents
+// it matches. Its queries are ANDed together. Format restricts matches to
+// json or xml documents.
+type QBE struct {
+	Queries  []QBEQuery
+	Filtered *bool
+	Score    string
+	Format   string
+}
+
+// QBEQuery is a query of a QBE. Names are JSON properties or XML elements
+// in Namespace, and must be XML names without a colon. Values are strings,
+// numbers, booleans or nil.
+type QBEQuery interface {
+	qbeNode() (*qbeNode, error)
+}
+
+// QBEValueQuery matches a property or element with Value. Exact and Weight
+// are the $exact and $weight modifiers of a $value query.
+type QBEValueQuery struct {
+	Namespace string
+	Name      string
+	Value     any
+	Exact     *bool
+	Weight    float64
+}
+
+// QBEWordQuery matches a property or element containing Text
+type QBEWordQuery struct {
+	Namespace string
+	Name      string
+	Text      string
+	Exact     *bool
+	Weight    float64
+}
+
+// QBERangeQuery compares the value of a property or element with Value
+// using one of the QBELT… operators
+type QBERangeQuery struct {
+	Namespace string
+	Name      string
+	Operator  string
+	Value     any
+}
+
+// QBEContainerQuery matches a property or element containing all of Queries
+type QBEContainerQuery struct {
+	Namespace string
+	Name      string
+	Queries   []QBEQuery
+}
+
+// QBEAndQuery matches documents matching all of Queries
+type QBEAndQuery struct {
+	Queries []QBEQuery
+}
+
+// QBEOrQuery matches documents matching any of Queries
+type QBEOrQuery struct {
+	Queries []QBEQuery
+}
+
+// QBENotQuery matches documents not matching Query
+type QBENotQuery struct {
+	Query QBEQuery
+}
+
+// QBENearQuery matches documents where Queries match within Distance words
+// of each other
+type QBENearQuery struct {
+	Queries  []QBEQuery
+	Distance int64
+	Ordered  bool
+}
+
+// qbeNode is a query of a QBE. It is a JSON property, named with a $ when it
+// is an operator, or an XML element, in the QBE namespace when it is an
+// operator. It holds a value, or child nodes written as an array of objects
+// in JSON when list is set. Modifiers are sibling properties in JSON and
+// attributes in XML.
+type qbeNode struct {
+	namespace string
+	name      string
+	operator  bool
+	value     any
+	hasValue  bool
+	children  []*qbeNode
+	list      bool
+	modifiers []qbeModifier
+}
+
+type qbeModifier struct {
+	name  string
+	value any
+}
+
+func (n *qbeNode) modifier(name string, value any, set bool) *qbeNode {
+	if set {
+		n.modifiers = append(n.modifiers, qbeModifier{name: name, value: value})
+	}
+	return n
+}
+
+func (q *QBEValueQuery) qbeNode() (*qbeNode, error) {
+	if err := validQBEValue(q.Value); err != nil {
+		return nil, err
+	}
+	if q.Exact == nil && q.Weight == 0 {
+		return namedQBENode(q.Namespace, q.Name, &qbeNode{value: q.Value, hasValue: true})
+	}
+	value := &qbeNode{name: "value", operator: true, value: q.Value, hasValue: true}
+	value.modifier("exact", q.Exact, q.Exact != nil).modifier("weight", q.Weight, q.Weight != 0)
+	return namedQBENode(q.Namespace, q.Name, &qbeNode{children: []*qbeNode{value}})
+}
+
+func (q *QBEWordQuery) qbeNode() (*qbeNode, error) {
+	word := &qbeNode{name: "word", operator: true, value: q.Text, hasValue: true}
+	word.modifier("exact", q.Exact, q.Exact != nil).modifier("weight", q.Weight, q.Weight != 0)
+	return namedQBENode(q.Namespace, q.Name, &qbeNode{children: []*qbeNode{word}})
+}
+
+func (q *QBERangeQuery) qbeNode() (*qbeNode, error) {
+	if !qbeOperators[q.Operator] {
+		return nil, fmt.Errorf("invalid qbe operator: %s", q.Operator)
+	}
+	if err := validQBEValue(q.Value); err != nil {
+		return nil, err
+	}
+	comparison := &qbeNode{name: q.Operator, operator: true, value: q.Value, hasValue: true}
+	return namedQBENode(q.Namespace, q.Name, &qbeNode{children: []*qbeNode{comparison}})
+}
+
+func (q *QBEContainerQuery) qbeNode() (*qbeNode, error) {
+	children, err := qbeNodes(q.Queries)
+	if err != nil {
+		return nil, err
+	}
+	return namedQBENode(q.Namespace, q.Name, &qbeNode{children: children})
+}
+
+func (q *QBEAndQuery) qbeNode() (*qbeNode, error) {
+	children, err := qbeNodes(q.Queries)
+	return &qbeNode{name: "and", operator: true, children: children, list: true}, err
+}
+
+func (q *QBEOrQuery) qbeNode() (*qbeNode, error) {
+	children, err := qbeNodes(q.Queries)
+	return &qbeNode{name: "or", operator: true, children: children, list: true}, err
+}
+
+func (q *QBENotQuery) qbeNode() (*qbeNode, error) {
+	children, err := qbeNodes([]QBEQuery{q.Query})
+	return &qbeNode{name: "not", operator: true, children: children}, err
+}
+
+func (q *QBENearQuery) qbeNode() (*qbeNode, error) {
+	children, err := qbeNodes(q.Queries)
+	near := &qbeNode{name: "near", operator: true, children: children, list: true}
+	return near.modifier("distance", q.Distance, q.Distance != 0).modifier("ordered", true, q.Ordered), err
+}
+
+// namedQBENode names node after a property or element
+func namedQBENode(namespace string, name string, node *qbeNode) (*qbeNode, error) {
+	if name == "" {
+		return nil, errors.New("qbe query requires a name")
+	}
+	if !util.IsNCName(name) {
+		return nil, fmt.Errorf("invalid qbe name: %s", name)
+	}
+	node.namespace, node.name = namespace, name
+	return node, nil
+}
+
+// validQBEValue returns an error unless value is a string, number, boolean or
+// nil, the values a QBE can match in both JSON and XML
+func validQBEValue(value any) error {
+	if value == nil {
+		return nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	}
+	return fmt.Errorf("invalid qbe value of type %T", value)
+}
+
+// qbeNodes returns the nodes of queries, or an error when one is nil or
+// invalid
+func qbeNodes(queries []QBEQuery) ([]*qbeNode, error) {
+	nodes := make([]*qbeNode, 0, len(queries))
+	for _, query := range queries {
+		if query == nil || reflect.ValueOf(query).IsNil() {
+			return nil, errors.New("qbe query is nil")
+		}
+		node, err := query.qbeNode()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// query returns the root node of the QBE, holding its $query
+func (q *QBE) query() (*qbeNode, error) {
+	children, err := qbeNodes(q.Queries)
+	if err != nil {
+		return nil, err
+	}
+	if q.Filtered != nil {
+		children = append(children, &qbeNode{name: "filtered", operator: true, value: *q.Filtered, hasValue: true})
+	}
+	if q.Score != "" {
+		children = append(children, &qbeNode{name: "score", operator: true, value: q.Score, hasValue: true})
+	}
+	root := &qbeNode{name: "qbe", operator: true, children: []*qbeNode{{name: "query", operator: true, children: children}}}
+	if q.Format != "" {
+		root.children = append(root.children, &qbeNode{name: "format", operator: true, value: q.Format, hasValue: true})
+	}
+	return root, nil
+}
+
+// SerializeQBE returns qbe in the XML or JSON QBE format
+func SerializeQBE(qbe *QBE, format int) ([]byte, error) {
+	root, err := qbe.query()
+	if err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	if format == handle.JSON {
+		err = root.writeJSONMembers(buffer)
+		return buffer.Bytes(), err
+	}
+	encoder := xml.NewEncoder(buffer)
+	root.modifiers = []qbeModifier{{name: "xmlns:q", value: qbeNamespace}}
+	if err = root.encodeXML(encoder, false); err == nil {
+		err = encoder.Flush()
+	}
+	return buffer.Bytes(), err
+}
+
+// NewQBEHandle returns a handle sending qbe as the query of a QBE request
+func NewQBEHandle(qbe *QBE, format int) (*handle.RawHandle, error) {
+	serialized, err := SerializeQBE(qbe, format)
+	if err != nil {
+		return nil, err
+	}
+	qbeHandle := &handle.RawHandle{Format: format}
+	qbeHandle.Serialize(serialized)
+	return qbeHandle, nil
+}
+
+// writeJSONMembers writes the children of n as a JSON object
+func (n *qbeNode) writeJSONMembers(buffer *bytes.Buffer) error {
+	buffer.WriteByte('{')
+	for i, child := range jsonMembers(n.children) {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := child.writeJSON(buffer); err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte('}')
+	return nil
+}
+
+// jsonMembers returns the children of a JSON object. A JSON object cannot hold
+// the same property twice, so children whose properties clash, such as two
+// range queries on one property, are gathered in an $and together with any
+// $and among the children.
+func jsonMembers(children []*qbeNode) []*qbeNode {
+	counts := map[string]int{}
+	for _, child := range children {
+		for _, key := range child.jsonKeys() {
+			counts[key]++
+		}
+	}
+	clashes := func(child *qbeNode) bool {
+		for _, key := range child.jsonKeys() {
+			if counts[key] > 1 {
+				return true
+			}
+		}
+		return false
+	}
+	gathered := false
+	for _, child := range children {
+		gathered = gathered || clashes(child)
+	}
+	if !gathered {
+		return children
+	}
+	and := &qbeNode{name: "and", operator: true, list: true}
+	members := make([]*qbeNode, 0, len(children))
+	for _, child := range children {
+		if !clashes(child) && !(child.operator && child.name == "and") {
+			members = append(members, child)
+			continue
+		}
+		if len(and.children) == 0 {
+			members = append(members, and)
+		}
+		and.children = append(and.children, child)
+	}
+	return members
+}
+
+// jsonKeys returns the properties n is written as in a JSON object
+func (n *qbeNode) jsonKeys() []string {
+	key := n.name
+	if n.operator {
+		key = "$" + key
+	}
+	keys := []string{key}
+	for _, modifier := range n.modifiers {
+		keys = append(keys, "$"+modifier.name)
+	}
+	return keys
+}
+
+// writeJSON writes n and its modifiers as properties of a JSON object
+func (n *qbeNode) writeJSON(buffer *bytes.Buffer) error {
+	if err := writeQBEJSONValue(buffer, n.jsonKeys()[0]); err != nil {
+		return err
+	}
+	buffer.WriteByte(':')
+	switch {
+	case n.hasValue:
+		if err := writeQBEJSONValue(buffer, n.value); err != nil {
+			return err
+		}
+	case n.list:
+		buffer.WriteByte('[')
+		for i, child := range n.children {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := (&qbeNode{children: []*qbeNode{child}}).writeJSONMembers(buffer); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	default:
+		if err := n.writeJSONMembers(buffer); err != nil {
+			return err
+		}
+	}
+	for _, modifier := range n.modifiers {
+		buffer.WriteByte(',')
+		if err := writeQBEJSONValue(buffer, "$"+modifier.name); err != nil {
+			return err
+		}
+		buffer.WriteByte(':')
+		if err := writeQBEJSONValue(buffer, modifier.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeQBEJSONValue(buffer *bytes.Buffer, value any) error {
+	serialized, err := marshalCTSJSON(value)
+	if err != nil {
+		return err
+	}
+	buffer.Write(serialized)
+	return nil
+}
+
+// encodeXML writes n as an element. Operators are written with the q prefix
+// so that elements without a namespace stay out of the QBE namespace;
+// inNamespace tells whether an ancestor declared a default namespace.
+func (n *qbeNode) encodeXML(e *xml.Encoder, inNamespace bool) error {
+	start := xml.StartElement{Name: xml.Name{Space: n.namespace, Local: n.name}}
+	if n.operator {
+		start.Name = xml.Name{Local: "q:" + n.name}
+	} else if n.namespace == "" && inNamespace {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}})
+	}
+	for _, modifier := range n.modifiers {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: modifier.name}, Value: qbeXMLText(modifier.value)})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if n.hasValue {
+		if err := e.EncodeToken(xml.CharData(qbeXMLText(n.value))); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.children {
+		if err := child.encodeXML(e, n.namespace != "" || (inNamespace && n.operator)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func qbeXMLText(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case *bool:
+		return fmt.Sprint(*typed)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// ExecuteQBE runs a query by example. Query is the QBE; View ViewOutput
+// returns the combined query the QBE is converted to and Validate validates
+// it.
+func ExecuteQBE(c *clients.Client, request *Request, response handle.ResponseHandle) error {
+	req, err := request.queryRequest(c, "/qbe"+request.qbeParameters(c))
+	if err != nil {
+		return err
+	}
+	return util.Execute(c, req, response)
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+)
+
+var exampleQBE = &QBE{
+	Queries: []QBEQuery{
+		&QBEValueQuery{Name: "author", Value: "Mark Twain"},
+		&QBERangeQuery{Name: "price", Operator: QBELT, Value: 10},
+		&QBEOrQuery{Queries: []QBEQuery{
+			&QBEWordQuery{Name: "title", Text: "river", Exact: Bool(false)},
+			&QBEContainerQuery{Name: "publisher", Queries: []QBEQuery{&QBEValueQuery{Name: "city", Value: "Hartford", Weight: 2}}},
+		}},
+		&QBENotQuery{Query: &QBEValueQuery{Name: "draft", Value: true}},
+		&QBENearQuery{Queries: []QBEQuery{&QBEWordQuery{Name: "summary", Text: "raft"}, &QBEWordQuery{Name: "summary", Text: "river"}}, Distance: 5, Ordered: true},
+	},
+	Filtered: Bool(false),
+	Format:   "json",
+}
+
+func TestQBEJSON(t *testing.T) {
+	want := `{"$query":{"author":"Mark Twain","price":{"$lt":10},` +
+		`"$or":[{"title":{"$word":"river","$exact":false}},{"publisher":{"city":{"$value":"Hartford","$weight":2}}}],` +
+		`"$not":{"draft":true},` +
+		`"$near":[{"summary":{"$word":"raft"}},{"summary":{"$word":"river"}}],"$distance":5,"$ordered":true,` +
+		`"$filtered":false},"$format":"json"}`
+	serialized, err := SerializeQBE(exampleQBE, handle.JSON)
+	if err != nil || string(serialized) != want {
+		t.Errorf("QBE Results = %s %v, Want = %+v", serialized, err, want)
+	}
+}
+
+func TestQBEXML(t *testing.T) {
+	qbe := &QBE{
+		Queries: []QBEQuery{
+			&QBEContainerQuery{Namespace: "http://example.com", Name: "book", Queries: []QBEQuery{
+				&QBEValueQuery{Namespace: "http://example.com", Name: "author", Value: "Mark Twain"},
+				&QBERangeQuery{Name: "price", Operator: QBEGE, Value: 5},
+			}},
+			&QBENearQuery{Queries: []QBEQuery{&QBEWordQuery{Name: "summary", Text: "raft", Weight: 1.5}}, Distance: 3},
+		},
+		Score: "logtf",
+	}
+	// elements without a namespace are taken out of the default namespace of their parent
+	want := `<q:qbe xmlns:q="http://marklogic.com/appservices/querybyexample"><q:query>` +
+		`<book xmlns="http://example.com"><author xmlns="http://example.com">Mark Twain</author><price xmlns=""><q:ge>5</q:ge></price></book>` +
+		`<q:near distance="3"><summary><q:word weight="1.5">raft</q:word></summary></q:near>` +
+		`<q:score>logtf</q:score></q:query></q:qbe>`
+	serialized, err := SerializeQBE(qbe, handle.XML)
+	if err != nil || string(serialized) != want {
+		t.Errorf("QBE Results = %s %v, Want = %+v", serialized, err, want)
+	}
+}
+
+func TestQBEErrors(t *testing.T) {
+	cases := map[string]*QBE{
+		"invalid qbe operator: $lt":                {Queries: []QBEQuery{&QBERangeQuery{Name: "price", Operator: "$lt", Value: 1}}},
+		"qbe query requires a name":                {Queries: []QBEQuery{&QBEAndQuery{Queries: []QBEQuery{&QBEWordQuery{Text: "a"}}}}},
+		"qbe query is nil":                         {Queries: []QBEQuery{&QBENotQuery{}}},
+		"invalid qbe name: bad name":               {Queries: []QBEQuery{&QBEValueQuery{Name: "bad name", Value: 1}}},
+		"invalid qbe value of type []string":       {Queries: []QBEQuery{&QBERangeQuery{Name: "price", Operator: QBELT, Value: []string{"1"}}}},
+		"invalid qbe value of type map[string]int": {Queries: []QBEQuery{&QBEValueQuery{Name: "price", Value: map[string]int{"a": 1}}}},
+	}
+	for want, qbe := range cases {
+		for _, format := range []int{handle.JSON, handle.XML} {
+			if _, err := NewQBEHandle(qbe, format); err == nil || err.Error() != want {
+				t.Errorf("NewQBEHandle Error = %v, Want = %+v", err, want)
+			}
+		}
+	}
+}
+
+func TestQBEJSONSameName(t *testing.T) {
+	qbe := &QBE{Queries: []QBEQuery{
+		&QBERangeQuery{Name: "price", Operator: QBEGT, Value: 5},
+		&QBEValueQuery{Name: "author", Value: "Mark Twain"},
+		&QBERangeQuery{Name: "price", Operator: QBELT, Value: 10},
+		&QBEAndQuery{Queries: []QBEQuery{&QBEWordQuery{Name: "title", Text: "river"}}},
+	}}
+	// a JSON object cannot repeat a property, so the clashing queries are ANDed
+	want := `{"$query":{"$and":[{"price":{"$gt":5}},{"price":{"$lt":10}},{"$and":[{"title":{"$word":"river"}}]}],"author":"Mark Twain"}}`
+	serialized, err := SerializeQBE(qbe, handle.JSON)
+	if err != nil || string(serialized) != want {
+		t.Errorf("QBE Results = %s %v, Want = %+v", serialized, err, want)
+	}
+}
+
+func TestExecuteQBE(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	qbe, _ := NewQBEHandle(exampleQBE, handle.JSON)
+	service.ExecuteQBE(&Request{Query: qbe, Start: 1, PageLength: 10, Collections: []string{"books"}}, &ResponseHandle{Format: handle.JSON})
+	want := recordedRequest{method: "POST", uri: "/qbe?start=1&pageLength=10&collection=books", body: qbe.Serialized()}
+	if *recorded != want {
+		t.Errorf("ExecuteQBE Request = %+v, Want = %+v", *recorded, want)
+	}
+	service.ExecuteQBE(&Request{Query: qbe, View: ViewOutput, Validate: true}, &CombinedQueryHandle{Format: handle.JSON})
+	if want := "/qbe?view=output&validate=true"; recorded.uri != want {
+		t.Errorf("ExecuteQBE Request = %+v, Want = %+v", recorded.uri, want)
+	}
+}
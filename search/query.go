@@ -94,11 +94,12 @@ func (qh *QueryHandle) Timestamp() string {
 
 // CombinedQuery represents https://docs.marklogic.com/guide/rest-dev/search#id_69918
 type CombinedQuery struct {
-	XMLName         xml.Name `xml:"http://marklogic.com/appservices/search search" json:"search"`
-	StructuredQuery Query    `xml:"http://marklogic.com/appservices/search query" json:"query,omitempty"`
-	QText           []string `xml:"http://marklogic.com/appservices/search qtext" json:"qtext,omitempty"`
-	SPARQL          string   `xml:"http://marklogic.com/appservices/search sparql" json:"sparql,omitempty"`
-	// TODO: Options SearchOptions
+	XMLName         xml.Name  `xml:"http://marklogic.com/appservices/search search" json:"search"`
+	StructuredQuery Query     `xml:"http://marklogic.com/appservices/search query" json:"query,omitempty"`
+	QText           []string  `xml:"http://marklogic.com/appservices/search qtext" json:"qtext,omitempty"`
+	SPARQL          string    `xml:"http://marklogic.com/appservices/search sparql" json:"sparql,omitempty"`
+	CTSQuery        *RawQuery `xml:",any,omitempty" json:"ctsquery,omitempty"`
+	Options         *Options  `xml:"http://marklogic.com/appservices/search options,omitempty" json:"options,omitempty"`
 }
 
 // Query represents http://docs.marklogic.com/guide/search-dev/structured-query#id_85307
@@ -209,7 +210,7 @@ type CollectionQuery struct {
 // ContainerQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87231
 type ContainerQuery struct {
 	XMLName       xml.Name     `xml:"http://marklogic.com/appservices/search container-query" json:"-"`
-	Element       QueryElement `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
+	Element       QueryElement `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitzero"`
 	JSONKey       string       `xml:"http://marklogic.com/appservices/search json-key,omitempty" json:"json-key,omitempty"`
 	FragmentScope string       `xml:"http://marklogic.com/appservices/search fragment-scope,omitempty" json:"fragment-scope,omitempty"`
 	Queries       []any        `xml:",any" json:"queries"`
@@ -218,15 +219,15 @@ type ContainerQuery struct {
 // QueryElement represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87231
 type QueryElement struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search element" json:"-"`
-	Namespace string   `xml:"ns,attr"`
-	Local     string   `xml:"name,attr"`
+	Namespace string   `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	Local     string   `xml:"name,attr" json:"name"`
 }
 
 // QueryAttribute represents http://docs.marklogic.com/guide/search-dev/structured-query#id_83393
 type QueryAttribute struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search attribute" json:"-"`
-	Namespace string   `xml:"ns,attr"`
-	Local     string   `xml:"name,attr"`
+	Namespace string   `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	Local     string   `xml:"name,attr" json:"name"`
 }
 
 // DocumentQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_27172
@@ -250,10 +251,12 @@ type LocksQuery struct {
 // RangeQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_83393
 type RangeQuery struct {
 	XMLName       xml.Name       `xml:"http://marklogic.com/appservices/search range-query" json:"-"`
-	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
-	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitempty"`
+	Type          string         `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Collation     string         `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitzero"`
+	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitzero"`
 	JSONKey       string         `xml:"http://marklogic.com/appservices/search json-key,omitempty" json:"json-key,omitempty"`
-	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitempty"`
+	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitzero"`
 	PathIndex     string         `xml:"http://marklogic.com/appservices/search path-index,omitempty" json:"path-index,omitempty"`
 	FragmentScope string         `xml:"http://marklogic.com/appservices/search fragment-scope,omitempty" json:"fragment-scope,omitempty"`
 	Value         string         `xml:"http://marklogic.com/appservices/search value,omitempty" json:"value,omitempty"`
@@ -264,17 +267,17 @@ type RangeQuery struct {
 // FieldReference represents http://docs.marklogic.com/guide/search-dev/structured-query#id_83393
 type FieldReference struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search field" json:"-"`
-	Name      string   `xml:"name,attr"`
-	Collation string   `xml:"collation,attr"`
+	Name      string   `xml:"name,attr" json:"name"`
+	Collation string   `xml:"collation,attr,omitempty" json:"collation,omitempty"`
 }
 
 // ValueQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_39758
 type ValueQuery struct {
 	XMLName       xml.Name       `xml:"http://marklogic.com/appservices/search value-query" json:"-"`
-	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
-	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitempty"`
+	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitzero"`
+	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitzero"`
 	JSONKey       string         `xml:"http://marklogic.com/appservices/search json-key,omitempty" json:"json-key,omitempty"`
-	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitempty"`
+	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitzero"`
 	FragmentScope string         `xml:"http://marklogic.com/appservices/search fragment-scope,omitempty" json:"fragment-scope,omitempty"`
 	Text          []string       `xml:"http://marklogic.com/appservices/search text,omitempty" json:"text,omitempty"`
 	TermOptions   []string       `xml:"http://marklogic.com/appservices/search term-option,omitempty" json:"term-option,omitempty"`
@@ -284,10 +287,10 @@ type ValueQuery struct {
 // WordQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_18990
 type WordQuery struct {
 	XMLName       xml.Name       `xml:"http://marklogic.com/appservices/search word-query" json:"-"`
-	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitempty"`
-	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitempty"`
+	Element       QueryElement   `xml:"http://marklogic.com/appservices/search element,omitempty" json:"element,omitzero"`
+	Attribute     QueryAttribute `xml:"http://marklogic.com/appservices/search attribute,omitempty" json:"attribute,omitzero"`
 	JSONKey       string         `xml:"http://marklogic.com/appservices/search json-key,omitempty" json:"json-key,omitempty"`
-	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitempty"`
+	Field         FieldReference `xml:"http://marklogic.com/appservices/search field,omitempty" json:"field,omitzero"`
 	FragmentScope string         `xml:"http://marklogic.com/appservices/search fragment-scope,omitempty" json:"fragment-scope,omitempty"`
 	Text          []string       `xml:"http://marklogic.com/appservices/search text,omitempty" json:"text,omitempty"`
 	TermOptions   []string       `xml:"http://marklogic.com/appservices/search term-option,omitempty" json:"term-option,omitempty"`
@@ -297,19 +300,19 @@ type WordQuery struct {
 // QueryParent represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87280
 type QueryParent struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search parent" json:"-"`
-	Namespace string   `xml:"ns,attr"`
-	Local     string   `xml:"name,attr"`
+	Namespace string   `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	Local     string   `xml:"name,attr" json:"name"`
 }
 
 // HeatMap represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87280
 type HeatMap struct {
 	XMLName xml.Name `xml:"http://marklogic.com/appservices/search heatmap" json:"-"`
-	North   float64  `xml:"n,attr"`
-	East    float64  `xml:"e,attr"`
-	South   float64  `xml:"s,attr"`
-	West    float64  `xml:"w,attr"`
-	Latdivs int64    `xml:"latdivs,attr"`
-	Londivs int64    `xml:"londivs,attr"`
+	North   float64  `xml:"n,attr" json:"n"`
+	East    float64  `xml:"e,attr" json:"e"`
+	South   float64  `xml:"s,attr" json:"s"`
+	West    float64  `xml:"w,attr" json:"w"`
+	Latdivs int64    `xml:"latdivs,attr" json:"latdivs"`
+	Londivs int64    `xml:"londivs,attr" json:"londivs"`
 }
 
 // Point represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87280
@@ -344,11 +347,11 @@ type Polygon struct {
 // GeoElemQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_87280
 type GeoElemQuery struct {
 	XMLName      xml.Name     `xml:"http://marklogic.com/appservices/search geo-elem-query" json:"-"`
-	Parent       QueryParent  `xml:"http://marklogic.com/appservices/search parent,omitempty" json:"parent,omitempty"`
-	Element      QueryElement `xml:"http://marklogic.com/appservices/search element" json:"element,omitempty"`
+	Parent       QueryParent  `xml:"http://marklogic.com/appservices/search parent,omitempty" json:"parent,omitzero"`
+	Element      QueryElement `xml:"http://marklogic.com/appservices/search element" json:"element,omitzero"`
 	GeoOptions   []string     `xml:"http://marklogic.com/appservices/search geo-option,omitempty" json:"geo-option,omitempty"`
 	FacetOptions []string     `xml:"http://marklogic.com/appservices/search facet-option,omitempty" json:"facet-option,omitempty"`
-	HeatMap      HeatMap      `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitempty"`
+	HeatMap      HeatMap      `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitzero"`
 	Points       []*Point     `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
 	Boxes        []*Box       `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
 	Circles      []*Circle    `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
@@ -358,26 +361,26 @@ type GeoElemQuery struct {
 // Lat represents http://docs.marklogic.com/guide/search-dev/structured-query#id_18303
 type Lat struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search lat" json:"-"`
-	Namespace string   `xml:"ns,attr"`
-	Local     string   `xml:"name,attr"`
+	Namespace string   `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	Local     string   `xml:"name,attr" json:"name"`
 }
 
 // Lon represents http://docs.marklogic.com/guide/search-dev/structured-query#id_18303
 type Lon struct {
 	XMLName   xml.Name `xml:"http://marklogic.com/appservices/search lon" json:"-"`
-	Namespace string   `xml:"ns,attr"`
-	Local     string   `xml:"name,attr"`
+	Namespace string   `xml:"ns,attr,omitempty" json:"ns,omitempty"`
+	Local     string   `xml:"name,attr" json:"name"`
 }
 
 // GeoElemPairQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_18303
 type GeoElemPairQuery struct {
 	XMLName      xml.Name    `xml:"http://marklogic.com/appservices/search geo-elem-pair-query" json:"-"`
-	Parent       QueryParent `xml:"http://marklogic.com/appservices/search parent,omitempty" json:"parent,omitempty"`
-	Lat          Lat         `xml:"http://marklogic.com/appservices/search lat" json:"lat,omitempty"`
-	Lon          Lon         `xml:"http://marklogic.com/appservices/search lon" json:"lon,omitempty"`
+	Parent       QueryParent `xml:"http://marklogic.com/appservices/search parent,omitempty" json:"parent,omitzero"`
+	Lat          Lat         `xml:"http://marklogic.com/appservices/search lat" json:"lat,omitzero"`
+	Lon          Lon         `xml:"http://marklogic.com/appservices/search lon" json:"lon,omitzero"`
 	GeoOptions   []string    `xml:"http://marklogic.com/appservices/search geo-option,omitempty" json:"geo-option,omitempty"`
 	FacetOptions []string    `xml:"http://marklogic.com/appservices/search facet-option,omitempty" json:"facet-option,omitempty"`
-	HeatMap      HeatMap     `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitempty"`
+	HeatMap      HeatMap     `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitzero"`
 	Points       []*Point    `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
 	Boxes        []*Box      `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
 	Circles      []*Circle   `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
@@ -387,12 +390,12 @@ type GeoElemPairQuery struct {
 // GeoAttrPairQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_67897
 type GeoAttrPairQuery struct {
 	XMLName      xml.Name    `xml:"http://marklogic.com/appservices/search geo-attr-pair-query" json:"-"`
-	Parent       QueryParent `xml:"http://marklogic.com/appservices/search parent" json:"parent,omitempty"`
-	Lat          Lat         `xml:"http://marklogic.com/appservices/search lat" json:"lat,omitempty"`
-	Lon          Lon         `xml:"http://marklogic.com/appservices/search lon" json:"lon,omitempty"`
+	Parent       QueryParent `xml:"http://marklogic.com/appservices/search parent" json:"parent,omitzero"`
+	Lat          Lat         `xml:"http://marklogic.com/appservices/search lat" json:"lat,omitzero"`
+	Lon          Lon         `xml:"http://marklogic.com/appservices/search lon" json:"lon,omitzero"`
 	GeoOptions   []string    `xml:"http://marklogic.com/appservices/search geo-option,omitempty" json:"geo-option,omitempty"`
 	FacetOptions []string    `xml:"http://marklogic.com/appservices/search facet-option,omitempty" json:"facet-option,omitempty"`
-	HeatMap      HeatMap     `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitempty"`
+	HeatMap      HeatMap     `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitzero"`
 	Points       []*Point    `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
 	Boxes        []*Box      `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
 	Circles      []*Circle   `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
@@ -405,13 +408,160 @@ type GeoPathQuery struct {
 	PathIndex    string     `xml:"http://marklogic.com/appservices/search path-index,omitempty" json:"path-index,omitempty"`
 	GeoOptions   []string   `xml:"http://marklogic.com/appservices/search geo-option,omitempty" json:"geo-option,omitempty"`
 	FacetOptions []string   `xml:"http://marklogic.com/appservices/search facet-option,omitempty" json:"facet-option,omitempty"`
-	HeatMap      HeatMap    `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitempty"`
+	HeatMap      HeatMap    `xml:"http://marklogic.com/appservices/search heatmap,omitempty" json:"heatmap,omitzero"`
 	Points       []*Point   `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
 	Boxes        []*Box     `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
 	Circles      []*Circle  `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
 	Polygons     []*Polygon `xml:"http://marklogic.com/appservices/search polygon,omitempty" json:"polygon,omitempty"`
 }
 
+// RangeConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_38268
+type RangeConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search range-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Values         []string `xml:"http://marklogic.com/appservices/search value,omitempty" json:"value,omitempty"`
+	RangeOperator  string   `xml:"http://marklogic.com/appservices/search range-operator,omitempty" json:"range-operator,omitempty"`
+	RangeOptions   []string `xml:"http://marklogic.com/appservices/search range-option,omitempty" json:"range-option,omitempty"`
+}
+
+// ValueConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_66932
+type ValueConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search value-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Text           []string `xml:"http://marklogic.com/appservices/search text,omitempty" json:"text,omitempty"`
+	Weight         float64  `xml:"http://marklogic.com/appservices/search weight,omitempty" json:"weight,omitempty"`
+}
+
+// WordConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_66331
+type WordConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search word-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Text           []string `xml:"http://marklogic.com/appservices/search text,omitempty" json:"text,omitempty"`
+	Weight         float64  `xml:"http://marklogic.com/appservices/search weight,omitempty" json:"weight,omitempty"`
+}
+
+// CollectionConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_30776
+type CollectionConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search collection-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	URIs           []string `xml:"http://marklogic.com/appservices/search uri,omitempty" json:"uri,omitempty"`
+}
+
+// ElementConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_34632
+type ElementConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search element-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Queries        []any    `xml:",any" json:"queries"`
+}
+
+// PropertiesConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_86489
+type PropertiesConstraintQuery struct {
+	XMLName        xml.Name `xml:"http://marklogic.com/appservices/search properties-constraint-query" json:"-"`
+	ConstraintName string   `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Queries        []any    `xml:",any" json:"queries"`
+}
+
+// CustomConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_16428
+type CustomConstraintQuery struct {
+	XMLName        xml.Name   `xml:"http://marklogic.com/appservices/search custom-constraint-query" json:"-"`
+	ConstraintName string     `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Text           []string   `xml:"http://marklogic.com/appservices/search text,omitempty" json:"text,omitempty"`
+	Points         []*Point   `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
+	Boxes          []*Box     `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
+	Circles        []*Circle  `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
+	Polygons       []*Polygon `xml:"http://marklogic.com/appservices/search polygon,omitempty" json:"polygon,omitempty"`
+}
+
+// GeospatialConstraintQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_67520
+type GeospatialConstraintQuery struct {
+	XMLName        xml.Name   `xml:"http://marklogic.com/appservices/search geospatial-constraint-query" json:"-"`
+	ConstraintName string     `xml:"http://marklogic.com/appservices/search constraint-name" json:"constraint-name"`
+	Points         []*Point   `xml:"http://marklogic.com/appservices/search point,omitempty" json:"point,omitempty"`
+	Boxes          []*Box     `xml:"http://marklogic.com/appservices/search box,omitempty" json:"box,omitempty"`
+	Circles        []*Circle  `xml:"http://marklogic.com/appservices/search circle,omitempty" json:"circle,omitempty"`
+	Polygons       []*Polygon `xml:"http://marklogic.com/appservices/search polygon,omitempty" json:"polygon,omitempty"`
+}
+
+// TrueQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_95349
+type TrueQuery struct {
+	XMLName xml.Name `xml:"http://marklogic.com/appservices/search true-query" json:"-"`
+}
+
+// FalseQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_56011
+type FalseQuery struct {
+	XMLName xml.Name `xml:"http://marklogic.com/appservices/search false-query" json:"-"`
+}
+
+// OperatorState represents http://docs.marklogic.com/guide/search-dev/structured-query#id_39984
+type OperatorState struct {
+	XMLName      xml.Name `xml:"http://marklogic.com/appservices/search operator-state" json:"-"`
+	OperatorName string   `xml:"http://marklogic.com/appservices/search operator-name" json:"operator-name"`
+	StateName    string   `xml:"http://marklogic.com/appservices/search state-name" json:"state-name"`
+}
+
+// Temporal operators of a period range or period compare query. The aln
+// operators are Allen's interval relations and the iso operators those of
+// ISO SQL 2011.
+const (
+	ALNEquals       = "aln_equals"
+	ALNContains     = "aln_contains"
+	ALNContainedBy  = "aln_contained_by"
+	ALNMeets        = "aln_meets"
+	ALNMetBy        = "aln_met_by"
+	ALNBefore       = "aln_before"
+	ALNAfter        = "aln_after"
+	ALNStarts       = "aln_starts"
+	ALNStartedBy    = "aln_started_by"
+	ALNFinishes     = "aln_finishes"
+	ALNFinishedBy   = "aln_finished_by"
+	ALNOverlaps     = "aln_overlaps"
+	ALNOverlappedBy = "aln_overlapped_by"
+	ISOContains     = "iso_contains"
+	ISOOverlaps     = "iso_overlaps"
+	ISOSucceeds     = "iso_succeeds"
+	ISOPrecedes     = "iso_precedes"
+	ISOImmSucceeds  = "iso_imm_succeeds"
+	ISOImmPrecedes  = "iso_imm_precedes"
+	ISOEquals       = "iso_equals"
+)
+
+// PeriodRangeQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_76454
+type PeriodRangeQuery struct {
+	XMLName          xml.Name  `xml:"http://marklogic.com/appservices/search period-range-query" json:"-"`
+	Axes             []string  `xml:"http://marklogic.com/appservices/search axis" json:"axis"`
+	TemporalOperator string    `xml:"http://marklogic.com/appservices/search temporal-operator" json:"temporal-operator"`
+	Periods          []*Period `xml:"http://marklogic.com/appservices/search period" json:"period"`
+	Options          []string  `xml:"http://marklogic.com/appservices/search option,omitempty" json:"option,omitempty"`
+}
+
+// Period represents http://docs.marklogic.com/guide/search-dev/structured-query#id_76454
+// Start and End are xs:dateTime values.
+type Period struct {
+	XMLName xml.Name `xml:"http://marklogic.com/appservices/search period" json:"-"`
+	Start   string   `xml:"http://marklogic.com/appservices/search period-start" json:"period-start"`
+	End     string   `xml:"http://marklogic.com/appservices/search period-end" json:"period-end"`
+}
+
+// PeriodCompareQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_19798
+type PeriodCompareQuery struct {
+	XMLName          xml.Name `xml:"http://marklogic.com/appservices/search period-compare-query" json:"-"`
+	Axis1            string   `xml:"http://marklogic.com/appservices/search axis1" json:"axis1"`
+	TemporalOperator string   `xml:"http://marklogic.com/appservices/search temporal-operator" json:"temporal-operator"`
+	Axis2            string   `xml:"http://marklogic.com/appservices/search axis2" json:"axis2"`
+	Options          []string `xml:"http://marklogic.com/appservices/search option,omitempty" json:"option,omitempty"`
+}
+
+// LSQTQuery represents http://docs.marklogic.com/guide/search-dev/structured-query#id_85930
+// Timestamp is an xs:dateTime at or before the collection's LSQT, the last
+// stable query time; without it the LSQT is used.
+type LSQTQuery struct {
+	XMLName            xml.Name `xml:"http://marklogic.com/appservices/search lsqt-query" json:"-"`
+	TemporalCollection string   `xml:"http://marklogic.com/appservices/search temporal-collection" json:"temporal-collection"`
+	Timestamp          string   `xml:"http://marklogic.com/appservices/search timestamp,omitempty" json:"timestamp,omitempty"`
+	Weight             float64  `xml:"http://marklogic.com/appservices/search weight,omitempty" json:"weight,omitempty"`
+	Options            []string `xml:"http://marklogic.com/appservices/search option,omitempty" json:"option,omitempty"`
+}
+
 // UnmarshalXML Query converts to XML
 func (q *Query) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	queries, err2 := SerializeXMLWithQueries(d, start)
@@ -426,19 +576,11 @@ func (q *OrQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return err2
 }
 
-type fakeAndQuery AndQuery
-
 // UnmarshalXML AndQuery converts to XML
 func (q *AndQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	fake := fakeAndQuery(*q)
-	err := d.DecodeElement(&fake, &start)
-	if err != nil {
-		return err
-	}
-	q.Ordered = fake.Ordered
-	queries, err2 := SerializeXMLWithQueries(d, start)
+	queries, err := decodeXMLQuery(d, map[string]any{"ordered": &q.Ordered})
 	q.Queries = queries
-	return err2
+	return err
 }
 
 // UnmarshalXML PositiveQuery converts to XML
@@ -462,21 +604,15 @@ func (q *NotQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return err2
 }
 
-type fakeNearQuery NearQuery
-
 // UnmarshalXML NearQuery converts to XML
 func (q *NearQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	fake := fakeNearQuery(*q)
-	err := d.DecodeElement(&fake, &start)
-	if err != nil {
-		return err
-	}
-	q.Ordered = fake.Ordered
-	q.Distance = fake.Distance
-	q.DistanceWeight = fake.DistanceWeight
-	queries, err2 := SerializeXMLWithQueries(d, start)
+	queries, err := decodeXMLQuery(d, map[string]any{
+		"ordered":         &q.Ordered,
+		"distance":        &q.Distance,
+		"distance-weight": &q.DistanceWeight,
+	})
 	q.Queries = queries
-	return err2
+	return err
 }
 
 // UnmarshalXML MatchingQuery converts to XML
@@ -500,21 +636,15 @@ func (q *PropertiesQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 	return err2
 }
 
-type fakeContainerQuery ContainerQuery
-
 // UnmarshalXML ContainerQuery converts to XML
 func (q *ContainerQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	fake := fakeContainerQuery(*q)
-	err := d.DecodeElement(&fake, &start)
-	if err != nil {
-		return err
-	}
-	q.Element = fake.Element
-	q.JSONKey = fake.JSONKey
-	q.FragmentScope = fake.FragmentScope
-	queries, err2 := SerializeXMLWithQueries(d, start)
+	queries, err := decodeXMLQuery(d, map[string]any{
+		"element":        &q.Element,
+		"json-key":       &q.JSONKey,
+		"fragment-scope": &q.FragmentScope,
+	})
 	q.Queries = queries
-	return err2
+	return err
 }
 
 // UnmarshalXML DocumentFragmentQuery converts to XML
@@ -531,6 +661,105 @@ func (q *LocksQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 	return err2
 }
 
+// UnmarshalXML ElementConstraintQuery converts to XML
+func (q *ElementConstraintQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	queries, err := decodeXMLQuery(d, map[string]any{"constraint-name": &q.ConstraintName})
+	q.Queries = queries
+	return err
+}
+
+// UnmarshalXML PropertiesConstraintQuery converts to XML
+func (q *PropertiesConstraintQuery) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	queries, err := decodeXMLQuery(d, map[string]any{"constraint-name": &q.ConstraintName})
+	q.Queries = queries
+	return err
+}
+
+// decodeXMLQuery decodes the child elements of a query up to the end of its
+// element: those named in fields into their targets and the others as
+// child queries
+func decodeXMLQuery(d *xml.Decoder, fields map[string]any) ([]any, error) {
+	var queries []any
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return queries, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if field, ok := fields[t.Name.Local]; ok {
+				if err := d.DecodeElement(field, &t); err != nil {
+					return queries, err
+				}
+				continue
+			}
+			q := stringToQueryStruct(t.Name.Local)
+			if q == nil {
+				if err := d.Skip(); err != nil {
+					return queries, err
+				}
+				continue
+			}
+			if err := d.DecodeElement(q, &t); err != nil {
+				return queries, err
+			}
+			queries = append(queries, q)
+		case xml.EndElement:
+			return queries, nil
+		}
+	}
+}
+
+// MarshalXML QueryElement leaves out an empty element
+func (qe QueryElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if qe.Namespace == "" && qe.Local == "" {
+		return nil
+	}
+	type element QueryElement
+	return e.EncodeElement(element(qe), start)
+}
+
+// MarshalXML QueryAttribute leaves out an empty attribute
+func (qa QueryAttribute) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if qa.Namespace == "" && qa.Local == "" {
+		return nil
+	}
+	type attribute QueryAttribute
+	return e.EncodeElement(attribute(qa), start)
+}
+
+// MarshalXML FieldReference leaves out an empty field
+func (fr FieldReference) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if fr.Name == "" && fr.Collation == "" {
+		return nil
+	}
+	type field FieldReference
+	return e.EncodeElement(field(fr), start)
+}
+
+// MarshalXML QueryParent leaves out an empty parent
+func (qp QueryParent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if qp.Namespace == "" && qp.Local == "" {
+		return nil
+	}
+	type parent QueryParent
+	return e.EncodeElement(parent(qp), start)
+}
+
+// MarshalXML HeatMap leaves out an empty heatmap
+func (hm HeatMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if hm.IsZero() {
+		return nil
+	}
+	type heatMap HeatMap
+	return e.EncodeElement(heatMap(hm), start)
+}
+
+// IsZero reports whether the heatmap has no bounds or divisions
+func (hm HeatMap) IsZero() bool {
+	return hm.North == 0 && hm.East == 0 && hm.South == 0 && hm.West == 0 && hm.Latdivs == 0 && hm.Londivs == 0
+}
+
 // SerializeXMLWithQueries Serializes text into Query struct
 func SerializeXMLWithQueries(d *xml.Decoder, start xml.StartElement) ([]any, error) {
 	var queries []any
@@ -547,7 +776,7 @@ func SerializeXMLWithQueries(d *xml.Decoder, start xml.StartElement) ([]any, err
 				queries = append(queries, q)
 			case xml.EndElement:
 				e := xml.EndElement(t)
-				if e.Name.Space == "http://marklogic.com/appservices/search" && e.Name.Local == "queries" {
+				if e.Name == start.Name || (e.Name.Space == "http://marklogic.com/appservices/search" && e.Name.Local == "queries") {
 					return queries, err
 				}
 			}
@@ -635,6 +864,34 @@ func stringToQueryStruct(inputString string) interface{} {
 		return &GeoAttrPairQuery{}
 	case "geo-path-query":
 		return &GeoPathQuery{}
+	case "range-constraint-query":
+		return &RangeConstraintQuery{}
+	case "value-constraint-query":
+		return &ValueConstraintQuery{}
+	case "word-constraint-query":
+		return &WordConstraintQuery{}
+	case "collection-constraint-query":
+		return &CollectionConstraintQuery{}
+	case "element-constraint-query":
+		return &ElementConstraintQuery{}
+	case "properties-constraint-query":
+		return &PropertiesConstraintQuery{}
+	case "custom-constraint-query":
+		return &CustomConstraintQuery{}
+	case "geospatial-constraint-query":
+		return &GeospatialConstraintQuery{}
+	case "true-query":
+		return &TrueQuery{}
+	case "false-query":
+		return &FalseQuery{}
+	case "operator-state":
+		return &OperatorState{}
+	case "period-range-query":
+		return &PeriodRangeQuery{}
+	case "period-compare-query":
+		return &PeriodCompareQuery{}
+	case "lsqt-query":
+		return &LSQTQuery{}
 	default:
 		return nil
 	}
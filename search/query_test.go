@@ -72,3 +72,97 @@ func TestJSONQueryEncode(t *testing.T) {
 		t.Errorf("Query Results = %+v, Want = %+v", result, want)
 	}
 }
+
+func TestGeoPairQueryJSON(t *testing.T) {
+	// unset lat and lon are left out
+	query := Query{Queries: []any{GeoElemPairQuery{Points: []*Point{{Latitude: 1, Longitude: 2}}}}}
+	qh := QueryHandle{Format: handle.JSON}
+	qh.Serialize(query)
+	if result := qh.Serialized(); strings.Contains(result, `"lat"`) || strings.Contains(result, `"lon"`) {
+		t.Errorf("Query Results = %+v, Want = %+v", result, "no lat or lon")
+	}
+}
+
+func TestConstraintQueriesRoundTrip(t *testing.T) {
+	query := Query{
+		Queries: []any{
+			&AndQuery{
+				Queries: []any{
+					&RangeConstraintQuery{ConstraintName: "price", Values: []string{"10"}, RangeOperator: "LT"},
+					&ValueConstraintQuery{ConstraintName: "author", Text: []string{"Twain"}, Weight: 2},
+					&WordConstraintQuery{ConstraintName: "title", Text: []string{"river"}},
+					&CollectionConstraintQuery{ConstraintName: "tag", URIs: []string{"fiction"}},
+					&ElementConstraintQuery{ConstraintName: "chapter", Queries: []any{&TermQuery{Terms: []string{"raft"}}}},
+					&PropertiesConstraintQuery{ConstraintName: "props", Queries: []any{&TrueQuery{}}},
+					&CustomConstraintQuery{ConstraintName: "custom", Text: []string{"x"}},
+					&GeospatialConstraintQuery{ConstraintName: "near", Points: []*Point{{Latitude: 38.9, Longitude: -77}}},
+					&FalseQuery{},
+					&OperatorState{OperatorName: "sort", StateName: "date"},
+				},
+			},
+		},
+	}
+	for _, format := range []int{handle.XML, handle.JSON} {
+		qh := QueryHandle{Format: format}
+		qh.Serialize(query)
+		serialized := qh.Serialized()
+		deserialized := QueryHandle{Format: format}
+		deserialized.Deserialize([]byte(serialized))
+		var and any = deserialized.Get().Queries[0]
+		if value, isValue := and.(AndQuery); isValue {
+			// JSON queries are unwrapped into values
+			and = &value
+		}
+		if and, ok := and.(*AndQuery); !ok || len(and.Queries) != 10 {
+			t.Errorf("Query Results = %+v, Want = %+v", spew.Sdump(deserialized.Get()), spew.Sdump(query))
+		}
+		if result := deserialized.Serialized(); result != serialized {
+			t.Errorf("Query Results = %+v, Want = %+v", result, serialized)
+		}
+	}
+}
+
+func TestTemporalQueriesRoundTrip(t *testing.T) {
+	want := `{"query":{"queries":[{"and-query":{"queries":[` +
+		`{"period-range-query":{"axis":["valid"],"temporal-operator":"aln_contains","period":[{"period-start":"2020-01-01T00:00:00Z","period-end":"2021-01-01T00:00:00Z"}]}},` +
+		`{"period-compare-query":{"axis1":"system","temporal-operator":"iso_overlaps","axis2":"valid","option":["score-function=zero"]}},` +
+		`{"lsqt-query":{"temporal-collection":"orders","timestamp":"2021-06-01T00:00:00Z"}}]}}]}}`
+	qh := QueryHandle{Format: handle.JSON}
+	qh.Deserialize([]byte(want))
+	and, ok := qh.Get().Queries[0].(AndQuery)
+	if !ok || len(and.Queries) != 3 {
+		t.Fatalf("Query Results = %+v", spew.Sdump(qh.Get()))
+	}
+	periodRange, ok := and.Queries[0].(*PeriodRangeQuery)
+	if !ok || periodRange.TemporalOperator != ALNContains || len(periodRange.Periods) != 1 || periodRange.Periods[0].End != "2021-01-01T00:00:00Z" {
+		t.Errorf("PeriodRangeQuery Results = %+v", spew.Sdump(and.Queries[0]))
+	}
+	if lsqt, ok := and.Queries[2].(*LSQTQuery); !ok || lsqt.TemporalCollection != "orders" {
+		t.Errorf("LSQTQuery Results = %+v", spew.Sdump(and.Queries[2]))
+	}
+	if result := strings.TrimSpace(qh.Serialized()); result != want {
+		t.Errorf("Query Results = %+v, Want = %+v", result, want)
+	}
+
+	query := Query{Queries: []any{&AndQuery{Queries: and.Queries}}}
+	xh := QueryHandle{Format: handle.XML}
+	xh.Serialize(query)
+	serialized := xh.Serialized()
+	if !strings.Contains(serialized, `<period-range-query xmlns="http://marklogic.com/appservices/search"><axis xmlns="http://marklogic.com/appservices/search">valid</axis><temporal-operator xmlns="http://marklogic.com/appservices/search">aln_contains</temporal-operator><period xmlns="http://marklogic.com/appservices/search"><period-start xmlns="http://marklogic.com/appservices/search">2020-01-01T00:00:00Z</period-start>`) {
+		t.Errorf("Query Results = %+v", serialized)
+	}
+	deserialized := QueryHandle{Format: handle.XML}
+	deserialized.Deserialize([]byte(serialized))
+	if result := deserialized.Serialized(); result != serialized {
+		t.Errorf("Query Results = %+v, Want = %+v", result, serialized)
+	}
+}
+
+func TestPeriodIsNotAQuery(t *testing.T) {
+	qh := QueryHandle{Format: handle.XML}
+	qh.Deserialize([]byte(`<query xmlns="http://marklogic.com/appservices/search"><and-query><period><period-start>2020-01-01T00:00:00Z</period-start></period><true-query/></and-query></query>`))
+	and, ok := qh.Get().Queries[0].(*AndQuery)
+	if !ok || len(and.Queries) != 1 {
+		t.Errorf("Query Results = %+v, Want = %+v", spew.Sdump(qh.Get().Queries), "and-query of true-query")
+	}
+}
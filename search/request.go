@@ -0,0 +1,158 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	clients "github.com/ryanjdew/go-marklogic-go/clients"
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/util"
+)
+
+// Views select the parts of a search response that are returned
+const (
+	ViewResults  = "results"
+	ViewFacets   = "facets"
+	ViewMetadata = "metadata"
+	ViewAll      = "all"
+	ViewNone     = "none"
+	// ViewOutput returns the combined query a QBE is converted to
+	ViewOutput = "output"
+)
+
+// Request describes a search, suggest or delete by query request. Text is
+// sent as the q parameter and Query, a structured or combined query handle,
+// as the request body, so the two can be combined. Start and PageLength are
+// sent together when either is set; a PageLength of 0 with a Start returns no
+// results. Other zero values are left to the server defaults.
+type Request struct {
+	Text        string
+	Query       handle.Handle
+	Options     string
+	Start       int64
+	PageLength  int64
+	View        string
+	Directory   string
+	Collections []string
+	Categories  []string
+	Transform   *util.Transform
+	Timestamp   string
+	Transaction *util.Transaction
+	// PartialText is the text to complete and Limit the maximum number of
+	// suggestions of a suggest request
+	PartialText string
+	Limit       int64
+	// Validate validates the QBE of a QBE request
+	Validate bool
+	// paged sends Start and PageLength even when both are 0, as Search and
+	// StructuredSearch always have
+	paged bool
+}
+
+// searchParameters returns the query string of a search request
+func (r *Request) searchParameters(c *clients.Client) string {
+	return r.commonParameters(c, r.queryParameters())
+}
+
+// qbeParameters returns the query string of a QBE request
+func (r *Request) qbeParameters(c *clients.Client) string {
+	params := r.queryParameters()
+	if r.Validate {
+		params = util.RepeatingParameters(params, "validate", []string{"true"})
+	}
+	return r.commonParameters(c, params)
+}
+
+// queryParameters returns the parameters shared by search and QBE requests
+func (r *Request) queryParameters() string {
+	params := util.RepeatingParameters("?", "q", nonEmpty(r.Text))
+	params = util.RepeatingParameters(params, "options", nonEmpty(r.Options))
+	if r.paged || r.Start != 0 || r.PageLength != 0 {
+		params = util.RepeatingParameters(params, "start", []string{strconv.FormatInt(r.Start, 10)})
+		params = util.RepeatingParameters(params, "pageLength", []string{strconv.FormatInt(r.PageLength, 10)})
+	}
+	params = util.RepeatingParameters(params, "view", nonEmpty(r.View))
+	params = util.RepeatingParameters(params, "directory", nonEmpty(r.Directory))
+	params = util.RepeatingParameters(params, "collection", r.Collections)
+	params = util.RepeatingParameters(params, "category", r.Categories)
+	params = util.RepeatingParameters(params, "timestamp", nonEmpty(r.Timestamp))
+	if r.Transform != nil {
+		params = params + r.Transform.ToParameters()
+	}
+	return params
+}
+
+// suggestParameters returns the query string of a suggest request
+func (r *Request) suggestParameters(c *clients.Client) string {
+	params := util.RepeatingParameters("?", "partial-q", nonEmpty(r.PartialText))
+	params = util.RepeatingParameters(params, "q", nonEmpty(r.Text))
+	params = util.RepeatingParameters(params, "options", nonEmpty(r.Options))
+	if r.Limit != 0 {
+		params = util.RepeatingParameters(params, "limit", []string{strconv.FormatInt(r.Limit, 10)})
+	}
+	return r.commonParameters(c, params)
+}
+
+// deleteParameters returns the query string of a delete by query request
+func (r *Request) deleteParameters(c *clients.Client) string {
+	params := util.RepeatingParameters("?", "collection", r.Collections)
+	params = util.RepeatingParameters(params, "directory", nonEmpty(r.Directory))
+	return r.commonParameters(c, params)
+}
+
+func (r *Request) commonParameters(c *clients.Client, params string) string {
+	if params == "?" {
+		params = ""
+	}
+	params = util.AddDatabaseParam(params, c)
+	return util.AddTransactionParam(params, r.Transaction)
+}
+
+// nonEmpty returns value as a slice of parameter values, empty when value is
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// queryRequest builds a GET request, or a POST when the request has a query
+func (r *Request) queryRequest(c *clients.Client, uri string) (*http.Request, error) {
+	if r.Query == nil {
+		return util.BuildRequestFromHandle(c, "GET", uri, nil)
+	}
+	return util.BuildRequestFromHandle(c, "POST", uri, r.Query)
+}
+
+// Execute runs a search request
+func Execute(c *clients.Client, request *Request, response handle.ResponseHandle) error {
+	req, err := request.queryRequest(c, "/search"+request.searchParameters(c))
+	if err != nil {
+		return err
+	}
+	return util.Execute(c, req, response)
+}
+
+// ExecuteSuggest runs a suggest request
+func ExecuteSuggest(c *clients.Client, request *Request, response handle.ResponseHandle) error {
+	req, err := request.queryRequest(c, "/suggest"+request.suggestParameters(c))
+	if err != nil {
+		return err
+	}
+	return util.Execute(c, req, response)
+}
+
+// ExecuteDelete deletes the documents in the collections and directory of a
+// request. A request with neither is rejected, as the server would delete
+// every document.
+func ExecuteDelete(c *clients.Client, request *Request, response handle.ResponseHandle) error {
+	if len(request.Collections) == 0 && request.Directory == "" {
+		return errors.New("delete by query requires collections or a directory")
+	}
+	req, err := util.BuildRequestFromHandle(c, "DELETE", "/search"+request.deleteParameters(c), nil)
+	if err != nil {
+		return err
+	}
+	return util.Execute(c, req, response)
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	"github.com/ryanjdew/go-marklogic-go/test"
+	"github.com/ryanjdew/go-marklogic-go/util"
+)
+
+type recordedRequest struct {
+	method string
+	uri    string
+	body   string
+}
+
+func recordingClient() (*Service, *recordedRequest, func()) {
+	recorded := &recordedRequest{}
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*recorded = recordedRequest{method: r.Method, uri: r.URL.RequestURI(), body: string(body)}
+	}))
+	return NewService(client), recorded, server.Close
+}
+
+func TestExecute(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	request := &Request{
+		Text:        "title:\"war & peace\"",
+		Options:     "books",
+		Start:       11,
+		PageLength:  10,
+		View:        ViewAll,
+		Directory:   "/books/",
+		Collections: []string{"fiction", "classics"},
+		Categories:  []string{"content"},
+		Transform:   &util.Transform{Name: "summary"},
+		Timestamp:   "16000000",
+	}
+	if err := service.Execute(request, &handle.RawHandle{Format: handle.XML}); err != nil {
+		t.Fatalf("Execute Error = %v", err)
+	}
+	want := recordedRequest{method: "GET", uri: "/search?q=title%3A%22war+%26+peace%22&options=books&start=11&pageLength=10&view=all&directory=%2Fbooks%2F&collection=fiction&collection=classics&category=content&timestamp=16000000&transform=summary"}
+	if *recorded != want {
+		t.Errorf("Execute Request = %+v, Want = %+v", *recorded, want)
+	}
+	query := &QueryHandle{Format: handle.XML}
+	query.Serialize(Query{Queries: []any{CollectionQuery{URIs: []string{"fiction"}}}})
+	service.Execute(&Request{Text: "war", Query: query, Start: 1}, nil)
+	want = recordedRequest{method: "POST", uri: "/search?q=war&start=1&pageLength=0", body: query.Serialized()}
+	if *recorded != want {
+		t.Errorf("Execute Request = %+v, Want = %+v", *recorded, want)
+	}
+}
+
+func TestStructuredSearchZeroPage(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	query := &QueryHandle{Format: handle.JSON}
+	query.Serialize(Query{})
+	// a page length of 0 returns only the totals and facets
+	service.StructuredSearch(query, 0, 0, nil, nil)
+	if want := "/search?start=0&pageLength=0"; recorded.uri != want {
+		t.Errorf("StructuredSearch Request = %+v, Want = %+v", recorded.uri, want)
+	}
+}
+
+func TestSearchEscapesText(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	service.Search("a&b c", 1, 10, nil, nil)
+	if want := "/search?q=a%26b+c&start=1&pageLength=10"; recorded.uri != want {
+		t.Errorf("Search Request = %+v, Want = %+v", recorded.uri, want)
+	}
+}
+
+func TestExecuteSuggest(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	service.ExecuteSuggest(&Request{PartialText: "sh&", Text: "author:bard", Limit: 5, Options: "books"}, nil)
+	want := recordedRequest{method: "GET", uri: "/suggest?partial-q=sh%26&q=author%3Abard&options=books&limit=5"}
+	if *recorded != want {
+		t.Errorf("ExecuteSuggest Request = %+v, Want = %+v", *recorded, want)
+	}
+}
+
+func TestExecuteDelete(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	service.ExecuteDelete(&Request{Collections: []string{"old"}, Directory: "/tmp/"}, nil)
+	want := recordedRequest{method: "DELETE", uri: "/search?collection=old&directory=%2Ftmp%2F"}
+	if *recorded != want {
+		t.Errorf("ExecuteDelete Request = %+v, Want = %+v", *recorded, want)
+	}
+	*recorded = recordedRequest{}
+	if err := service.ExecuteDelete(&Request{}, nil); err == nil || recorded.method != "" {
+		t.Errorf("ExecuteDelete Error = %v %+v, Want = %+v", err, *recorded, "no request")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	service, recorded, closer := recordingClient()
+	defer closer()
+	service.Delete(map[string]string{"collection": "old"}, nil, nil)
+	want := recordedRequest{method: "DELETE", uri: "/search?collection=old"}
+	if *recorded != want {
+		t.Errorf("Delete Request = %+v, Want = %+v", *recorded, want)
+	}
+	*recorded = recordedRequest{}
+	for _, parameters := range []map[string]string{nil, {"q": "status:archived"}} {
+		if err := service.Delete(parameters, nil, nil); err == nil || recorded.method != "" {
+			t.Errorf("Delete(%v) Error = %v %+v, Want = %+v", parameters, err, *recorded, "no request")
+		}
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"net/http"
-	"strconv"
 
 	clients "github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
@@ -151,40 +151,31 @@ type FacetValue struct {
 
 // Search with text value
 func Search(c *clients.Client, text string, start int64, pageLength int64, transaction *util.Transaction, response handle.ResponseHandle) error {
-	params := "?q=" + text + "&start=" + strconv.FormatInt(start, 10) + "&pageLength=" + strconv.FormatInt(pageLength, 10)
-	params = util.AddDatabaseParam(params, c)
-	params = util.AddTransactionParam(params, transaction)
-	req, err := util.BuildRequestFromHandle(c, "GET", "/search"+params, nil)
-	if err != nil {
-		return err
-	}
-	return util.Execute(c, req, response)
+	return Execute(c, &Request{Text: text, Start: start, PageLength: pageLength, Transaction: transaction, paged: true}, response)
 }
 
 // Delete documents that match specified collection, directory, etc.
 func Delete(c *clients.Client, parameters map[string]string, transaction *util.Transaction, response handle.ResponseHandle) error {
-	params := util.MappedParameters("?", "", parameters)
-	params = util.AddDatabaseParam(params, c)
-	params = util.AddTransactionParam(params, transaction)
-	req, err := util.BuildRequestFromHandle(c, "DELETE", "/search"+params, nil)
-	if err != nil {
-		return err
+	request := &Request{Transaction: transaction}
+	for name, value := range parameters {
+		switch name {
+		case "collection":
+			request.Collections = nonEmpty(value)
+		case "directory":
+			request.Directory = value
+		default:
+			return fmt.Errorf("unsupported delete parameter: %s", name)
+		}
 	}
-	return util.Execute(c, req, response)
+	return ExecuteDelete(c, request, response)
 }
 
 // StructuredSearch searches with a structured query
 func StructuredSearch(c *clients.Client, query handle.Handle, start int64, pageLength int64, transaction *util.Transaction, response handle.ResponseHandle) error {
-	params := "?start=" + strconv.FormatInt(start, 10) + "&pageLength=" + strconv.FormatInt(pageLength, 10)
-	params = util.AddDatabaseParam(params, c)
-	if (transaction != nil) && (transaction.ID != "") {
-		params = util.AddTransactionParam(params, transaction)
-	}
-	req, err := util.BuildRequestFromHandle(c, "POST", "/search"+params, query)
-	if err != nil {
-		return err
+	if transaction != nil && transaction.ID == "" {
+		transaction = nil
 	}
-	return util.Execute(c, req, response)
+	return Execute(c, &Request{Query: query, Start: start, PageLength: pageLength, Transaction: transaction, paged: true}, response)
 }
 
 // UnmarshalXML for Match struct in a special way to handle highlighting matching text
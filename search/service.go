@@ -99,13 +99,71 @@ func (s *Service) StructuredSuggestions(query handle.Handle, partialQ string, li
 	return StructuredSuggestions(s.client, query, partialQ, limit, options, response)
 }
 
+// Execute runs a search described by a Request, which can combine query
+// text and a structured or combined query and select named options, a view,
+// collections, a directory, a transform and a point in time.
+//
+// Parameters:
+//
+//	request: Request with the query and search parameters
+//	response: ResponseHandle to populate with results
+func (s *Service) Execute(request *Request, response handle.ResponseHandle) error {
+	return Execute(s.client, request, response)
+}
+
+// ResultIterator returns an iterator over the results of request, fetching
+// request.PageLength results at a time.
+//
+// Parameters:
+//
+//	request: Request with the query and search parameters
+func (s *Service) ResultIterator(request *Request) *ResultIterator {
+	return NewResultIterator(s.client, request)
+}
+
+// ExecuteQBE runs a query by example, sent as request.Query, returning the
+// matching documents as a search response. With a View of ViewOutput the
+// response is the combined query the QBE is converted to, and with Validate
+// set the QBE is validated.
+//
+// Parameters:
+//
+//	request: Request with the QBE handle and search parameters
+//	response: ResponseHandle to populate with results
+func (s *Service) ExecuteQBE(request *Request, response handle.ResponseHandle) error {
+	return ExecuteQBE(s.client, request, response)
+}
+
+// ExecuteSuggest returns suggestions for request.PartialText, limited by
+// request.Text, request.Query and the named options of the Request.
+//
+// Parameters:
+//
+//	request: Request with PartialText, Limit and optional query and options
+//	response: SuggestionsResponseHandle to populate with suggestions
+func (s *Service) ExecuteSuggest(request *Request, response handle.ResponseHandle) error {
+	return ExecuteSuggest(s.client, request, response)
+}
+
+// ExecuteDelete removes the documents in request.Collections and
+// request.Directory.
+//
+// Parameters:
+//
+//	request: Request with Collections and/or Directory and an optional Transaction
+//	response: ResponseHandle to populate with deletion confirmation
+func (s *Service) ExecuteDelete(request *Request, response handle.ResponseHandle) error {
+	return ExecuteDelete(s.client, request, response)
+}
+
 // Delete removes documents matching the specified search criteria.
 // Useful for bulk deletion operations. Can be used within transactions for
-// consistent multi-step operations. Parameters are passed as query string pairs.
+// consistent multi-step operations. Parameters are passed as query string
+// pairs; only collection and directory are supported.
 //
 // Parameters:
 //
-//	parameters: Query parameters (e.g., map{"q": "status:archived"})
+//	parameters: Query parameters (e.g., map{"collection": "archived"})
 //	transaction: Optional transaction for atomic deletion
 //	response: ResponseHandle to populate with deletion confirmation
 func (s *Service) Delete(parameters map[string]string, transaction *util.Transaction, response handle.ResponseHandle) error {
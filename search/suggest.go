@@ -5,11 +5,9 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"net/http"
-	"strconv"
 
 	clients "github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
-	"github.com/ryanjdew/go-marklogic-go/util"
 )
 
 // SuggestionsResponse represents the search Suggestions from MarkLogic
@@ -97,28 +95,10 @@ func (srh *SuggestionsResponseHandle) Timestamp() string {
 
 // Suggest suggests query text based on a partial string query
 func Suggest(c *clients.Client, partialQ string, limit int64, options string, response handle.ResponseHandle) error {
-	uri := "/suggest?partial-q=" + partialQ + "&limit=" + strconv.FormatInt(limit, 10)
-	if options != "" {
-		uri = uri + "&options=" + options
-	}
-	uri = util.AddDatabaseParam(uri, c)
-	req, err := http.NewRequest("GET", c.Base()+uri, nil)
-	if err != nil {
-		return err
-	}
-	return util.Execute(c, req, response)
+	return ExecuteSuggest(c, &Request{PartialText: partialQ, Limit: limit, Options: options}, response)
 }
 
 // StructuredSuggestions suggests query text based off of a structured query
 func StructuredSuggestions(c *clients.Client, query handle.Handle, partialQ string, limit int64, options string, response handle.ResponseHandle) error {
-	uri := "/suggest?limit=" + strconv.FormatInt(limit, 10)
-	if options != "" {
-		uri = uri + "&options=" + options
-	}
-	uri = util.AddDatabaseParam(uri, c)
-	req, err := util.BuildRequestFromHandle(c, "POST", uri, query)
-	if err != nil {
-		return err
-	}
-	return util.Execute(c, req, response)
+	return ExecuteSuggest(c, &Request{Query: query, PartialText: partialQ, Limit: limit, Options: options}, response)
 }
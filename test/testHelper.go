@@ -18,6 +18,12 @@ func Client(resp string) (*clients.Client, *httptest.Server) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, resp)
 	})
+	return ClientWithHandler(handler)
+}
+
+// ClientWithHandler is exported for testing subpackages that need to inspect
+// requests or vary responses
+func ClientWithHandler(handler http.Handler) (*clients.Client, *httptest.Server) {
 	server := httptest.NewServer(handler)
 	client, _ := clients.NewClient(&clients.Connection{Host: "localhost", Port: 8000, Username: "admin", Password: "admin", AuthenticationType: clients.BasicAuth})
 	client.SetBase(server.URL)
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/ryanjdew/go-marklogic-go/clients"
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
@@ -76,6 +77,19 @@ func MappedParameters(params string, prefix string, values map[string]string) st
 	return params
 }
 
+// IsNCName tells whether name is an XML name without a colon
+func IsNCName(name string) bool {
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) {
+			continue
+		}
+		if i == 0 || !(unicode.IsDigit(r) || r == '-' || r == '.' || unicode.In(r, unicode.Mn, unicode.Mc)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
 // AddDatabaseParam is a utility function for adding the database parameter
 func AddDatabaseParam(params string, client *clients.Client) string {
 	if client.Database() != "" {
@@ -103,6 +117,17 @@ func AddTransactionParam(params string, transaction *Transaction) string {
 	return params
 }
 
+// StatusError is returned when the REST API responds with an error status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP call returned status %v", e.StatusCode)
+}
+
 // BuildRequestFromHandle builds a *http.Request based off a handle.Handle
 func BuildRequestFromHandle(c clients.RESTClient, method string, uri string, reqHandle handle.Handle) (*http.Request, error) {
 	reqType := ""
@@ -141,7 +166,9 @@ func Execute(c clients.RESTClient, req *http.Request, responseHandle handle.Resp
 	if respHandleNotNil {
 		respType = handle.FormatEnumToMimeType(responseHandle.GetFormat())
 	}
-	req.Header.Add("Accept", respType)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Add("Accept", respType)
+	}
 	resp, err := c.Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
@@ -150,7 +177,8 @@ func Execute(c clients.RESTClient, req *http.Request, responseHandle handle.Resp
 		return err
 	}
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("HTTP call returned status %v", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	if respHandleNotNil {
 		return responseHandle.AcceptResponse(resp)
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -23,3 +24,29 @@ func TestExecute(t *testing.T) {
 		t.Errorf("Result = %v, want %v", spew.Sdump(result), spew.Sdump(want))
 	}
 }
+
+func TestExecuteStatusError(t *testing.T) {
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte("changed"))
+	}))
+	defer server.Close()
+	req, _ := http.NewRequest("GET", client.Base(), nil)
+	err := Execute(client, req, &handle.RawHandle{Format: handle.JSON})
+	var statusError *StatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != http.StatusPreconditionFailed || statusError.Body != "changed" {
+		t.Errorf("Error = %+v, Want = %+v", err, StatusError{StatusCode: http.StatusPreconditionFailed, Body: "changed"})
+	}
+}
+
+func TestIsNCName(t *testing.T) {
+	cases := map[string]bool{
+		"name": true, "_a-1.b": true, "né": true,
+		"": false, "1a": false, "-a": false, "a:b": false, "a b": false,
+	}
+	for name, want := range cases {
+		if result := IsNCName(name); result != want {
+			t.Errorf("IsNCName(%q) Results = %+v, Want = %+v", name, result, want)
+		}
+	}
+}
@@ -2,9 +2,13 @@ package values
 
 import (
 	"bytes"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	handle "github.com/ryanjdew/go-marklogic-go/handle"
+	search "github.com/ryanjdew/go-marklogic-go/search"
 	"github.com/ryanjdew/go-marklogic-go/test"
 )
 
@@ -72,6 +76,30 @@ func TestQueryValues(t *testing.T) {
 	}
 }
 
+func TestQueryValuesWithCombinedQuery(t *testing.T) {
+	var path, body string
+	client, server := test.ClientWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		read, _ := io.ReadAll(r.Body)
+		body = string(read)
+		w.Write([]byte(exampleValuesResponse))
+	}))
+	defer server.Close()
+
+	queryHandle := &search.CombinedQueryHandle{Format: handle.JSON}
+	queryHandle.Serialize(search.CombinedQuery{
+		QText:   []string{"active"},
+		Options: &search.Options{Constraints: []search.Constraint{{Name: "status", Collection: &search.CollectionConstraint{Prefix: "/status/"}}}},
+	})
+	respHandle := &handle.RawHandle{Format: handle.XML}
+	if err := NewService(client).QueryValues("status", nil, queryHandle, respHandle); err != nil {
+		t.Errorf("QueryValues returned error: %v", err)
+	}
+	if want := "POST /values/status"; path != want || !strings.Contains(body, `"qtext":["active"]`) || !strings.Contains(body, `"prefix":"/status/"`) {
+		t.Errorf("QueryValues Results = %+v %+v, Want = %+v", path, body, want)
+	}
+}
+
 func TestAggregateValues(t *testing.T) {
 	aggregateResponse := `<?xml version="1.0" encoding="UTF-8"?>
 <aggregate xmlns="http://marklogic.com/appservices/search">